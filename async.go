@@ -0,0 +1,376 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// 异步队列写满时的处理策略
+const (
+	OverflowBlock      = "block"       // 阻塞等待队列有空位
+	OverflowDropNewest = "drop_newest" // 丢弃当前这条日志
+	OverflowDropOldest = "drop_oldest" // 丢弃队列中最早的一条日志
+	OverflowSync       = "sync"        // 改为同步写入
+)
+
+// AsyncStats 异步队列的统计信息
+type AsyncStats struct {
+	Enqueued uint64 // 进入队列的日志数
+	Dropped  uint64 // 被丢弃的日志数
+	Written  uint64 // 已写入的日志数（包括队列满时改为同步写入的日志）
+	Queued   int    // 当前队列中等待写入的日志数
+}
+
+// asyncQueue 每个 LogsLogger 独立的异步写入队列
+type asyncQueue struct {
+	ch         chan logItem
+	policy     string
+	keepErrors bool        // Error 及以上级别的日志不丢弃
+	owner      *LogsLogger // 创建它的日志器，只有它能关闭队列；With、Named 创建的子日志器共享时只刷新
+
+	mu        sync.RWMutex // 保护 closed，防止向已关闭的 ch 发送
+	closed    bool
+	done      chan struct{} // worker 退出后关闭
+	abandoned atomic.Bool   // 关闭超时后置为 true，worker 丢弃剩余日志
+
+	enqueued atomic.Uint64
+	dropped  atomic.Uint64
+	written  atomic.Uint64
+}
+
+var (
+	queuesMu sync.Mutex
+	queues   = make(map[*asyncQueue]struct{}) // 所有运行中的异步队列，Close 时统一排空
+)
+
+// validOverflowPolicy 检查队列溢出策略，空字符串视为 block
+func validOverflowPolicy(policy string) error {
+	switch policy {
+	case "", OverflowBlock, OverflowDropNewest, OverflowDropOldest, OverflowSync:
+		return nil
+	default:
+		return fmt.Errorf("unsupported overflow policy: %s", policy)
+	}
+}
+
+// queueOptions 返回 conf 中的队列长度和溢出策略，未设置时使用默认值
+func queueOptions(conf LogConf) (size int, policy string) {
+	size, policy = conf.QueueSize, conf.OverflowPolicy
+	if size <= 0 {
+		size = defaultLogChanSize
+	}
+	if policy == "" {
+		policy = OverflowBlock
+	}
+	return size, policy
+}
+
+func newAsyncQueue(conf LogConf, owner *LogsLogger) *asyncQueue {
+	size, policy := queueOptions(conf)
+
+	q := &asyncQueue{
+		ch:         make(chan logItem, size),
+		policy:     policy,
+		keepErrors: conf.NeverDropErrors,
+		owner:      owner,
+		done:       make(chan struct{}),
+	}
+
+	queuesMu.Lock()
+	queues[q] = struct{}{}
+	queuesMu.Unlock()
+
+	go q.worker()
+	return q
+}
+
+func (q *asyncQueue) worker() {
+	defer close(q.done)
+	for item := range q.ch {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+		if q.abandoned.Load() {
+			q.dropped.Add(1)
+			continue
+		}
+		writeItem(item)
+		q.written.Add(1)
+	}
+}
+
+// writeItem 写入队列中的一条日志
+func writeItem(item logItem) {
+	if item.sink != nil {
+		writeTo(item.sink.writer, item.line)
+		return
+	}
+	writeLine(item.out, item.level, item.line)
+}
+
+// push 按照溢出策略将日志放入队列，队列已关闭时改为同步写入
+func (q *asyncQueue) push(item logItem) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		q.writeSync(item)
+		return
+	}
+
+	select {
+	case q.ch <- item:
+		q.enqueued.Add(1)
+		return
+	default:
+	}
+
+	policy := q.policy
+	if q.keepErrors && item.level >= LogLevelError && (policy == OverflowDropNewest || policy == OverflowDropOldest) {
+		policy = OverflowBlock
+	}
+
+	switch policy {
+	case OverflowDropNewest:
+		q.dropped.Add(1)
+	case OverflowDropOldest:
+		for kept := 0; ; {
+			select {
+			case q.ch <- item:
+				q.enqueued.Add(1)
+				return
+			default:
+			}
+			if kept >= cap(q.ch) {
+				// 队列中都是不能丢弃的日志，改为阻塞等待
+				q.ch <- item
+				q.enqueued.Add(1)
+				return
+			}
+			select {
+			case head := <-q.ch:
+				if q.droppable(head) {
+					q.dropped.Add(1)
+				} else {
+					// 放回队尾，之后的日志会先于它写入
+					q.ch <- head
+					kept++
+				}
+			default:
+			}
+		}
+	case OverflowSync:
+		q.writeSync(item)
+	default:
+		q.ch <- item
+		q.enqueued.Add(1)
+	}
+}
+
+// droppable 队列写满时能否丢弃队列中的这条日志：Sync 的刷新标记不能丢弃，
+// 设置了 NeverDropErrors 时 Error 及以上级别的日志也不能丢弃
+func (q *asyncQueue) droppable(item logItem) bool {
+	if item.flushed != nil {
+		return false
+	}
+	return !q.keepErrors || item.level < LogLevelError
+}
+
+func (q *asyncQueue) writeSync(item logItem) {
+	writeItem(item)
+	q.written.Add(1)
+}
+
+// flush 等待在此之前进入队列的日志全部写入，ctx 结束时提前返回
+func (q *asyncQueue) flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		return nil
+	}
+	select {
+	case q.ch <- logItem{flushed: flushed}:
+	case <-ctx.Done():
+		q.mu.RUnlock()
+		return ctx.Err()
+	}
+	q.mu.RUnlock()
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close 停止接收新日志，并等待队列中的日志全部写入
+func (q *asyncQueue) close() {
+	q.closeContext(context.Background())
+}
+
+// closeContext 停止接收新日志并等待队列排空；ctx 先结束时放弃剩余日志，返回丢失的条数
+func (q *asyncQueue) closeContext(ctx context.Context) (lost int) {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.ch)
+	}
+	q.mu.Unlock()
+
+	defer func() {
+		queuesMu.Lock()
+		delete(queues, q)
+		queuesMu.Unlock()
+	}()
+
+	select {
+	case <-q.done:
+		return 0
+	case <-ctx.Done():
+		q.abandoned.Store(true)
+		return len(q.ch)
+	}
+}
+
+// release 日志器不再使用该队列：创建者关闭并排空队列，返回丢失的条数；
+// 共享队列的子日志器只等待已有的日志写完，队列继续由创建者使用
+func (q *asyncQueue) release(l *LogsLogger, ctx context.Context) (lost int) {
+	if q.owner != l {
+		q.flush(ctx)
+		return 0
+	}
+	return q.closeContext(ctx)
+}
+
+// matches 队列仍在运行且配置与 conf 一致
+func (q *asyncQueue) matches(conf LogConf) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	size, policy := queueOptions(conf)
+	return !q.closed && cap(q.ch) == size && q.policy == policy && q.keepErrors == conf.NeverDropErrors
+}
+
+func (q *asyncQueue) stats() AsyncStats {
+	return AsyncStats{
+		Enqueued: q.enqueued.Load(),
+		Dropped:  q.dropped.Load(),
+		Written:  q.written.Load(),
+		Queued:   len(q.ch),
+	}
+}
+
+// inherit 继承旧队列的计数，用于调整队列配置后保持统计连续
+func (q *asyncQueue) inherit(old *asyncQueue) {
+	q.enqueued.Add(old.enqueued.Load())
+	q.dropped.Add(old.dropped.Load())
+	q.written.Add(old.written.Load())
+}
+
+// closeAllQueues 排空并关闭所有异步队列，返回因 ctx 结束而丢失的日志条数
+func closeAllQueues(ctx context.Context) (lost int) {
+	queuesMu.Lock()
+	all := make([]*asyncQueue, 0, len(queues))
+	for q := range queues {
+		all = append(all, q)
+	}
+	queuesMu.Unlock()
+
+	for _, q := range all {
+		lost += q.closeContext(ctx)
+	}
+	return lost
+}
+
+// 异步写入设置 ---------------------------------------------------------------------
+// restartQueue 按当前配置重建异步队列，返回旧队列，由调用方解锁后排空；调用方需持有锁
+func (l *LogsLogger) restartQueue() *retiredOutput {
+	old := l.queue
+	l.queue = newAsyncQueue(l.logConf, l)
+	l.publish()
+	if old == nil {
+		return nil
+	}
+	return &retiredOutput{owner: l, queue: old, next: l.queue}
+}
+
+// SetAsyncQueueSize 设置异步队列长度
+func (l *LogsLogger) SetAsyncQueueSize(size int) error {
+	if size <= 0 {
+		return fmt.Errorf("invalid async queue size: %d", size)
+	}
+
+	mu2.Lock()
+	l.own()
+	l.logConf.QueueSize = size
+	var r *retiredOutput
+	if l.queue != nil {
+		r = l.restartQueue()
+	}
+	mu2.Unlock()
+	r.release()
+	return nil
+}
+
+// SetOverflowPolicy 设置异步队列写满时的处理策略：block、drop_newest、drop_oldest、sync
+func (l *LogsLogger) SetOverflowPolicy(policy string) error {
+	if err := validOverflowPolicy(policy); err != nil {
+		return err
+	}
+
+	mu2.Lock()
+	l.own()
+	l.logConf.OverflowPolicy = policy
+	var r *retiredOutput
+	if l.queue != nil {
+		r = l.restartQueue()
+	}
+	mu2.Unlock()
+	r.release()
+	return nil
+}
+
+// SetNeverDropErrors 设置 Error 及以上级别的日志在队列写满时是否也不丢弃
+func (l *LogsLogger) SetNeverDropErrors(never bool) {
+	mu2.Lock()
+	l.own()
+	l.logConf.NeverDropErrors = never
+	var r *retiredOutput
+	if l.queue != nil {
+		r = l.restartQueue()
+	}
+	mu2.Unlock()
+	r.release()
+}
+
+// AsyncStats 返回异步队列的统计信息，未启用异步写入时返回零值
+func (l *LogsLogger) AsyncStats() AsyncStats {
+	q := l.outputs().queue
+	if q == nil {
+		return AsyncStats{}
+	}
+	return q.stats()
+}
+
+// 全局日志器的异步写入设置
+func SetAsyncQueueSize(size int) error {
+	return globalLogger.SetAsyncQueueSize(size)
+}
+
+func SetOverflowPolicy(policy string) error {
+	return globalLogger.SetOverflowPolicy(policy)
+}
+
+func SetNeverDropErrors(never bool) {
+	globalLogger.SetNeverDropErrors(never)
+}
+
+func GetAsyncStats() AsyncStats {
+	return globalLogger.AsyncStats()
+}
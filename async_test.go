@@ -0,0 +1,264 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gateWriter 在 gate 关闭前阻塞所有写入，用来让异步队列写满
+type gateWriter struct {
+	gate    chan struct{}
+	started chan struct{}
+	once    sync.Once
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{gate: make(chan struct{}), started: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) lines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return strings.Split(strings.TrimSpace(w.buf.String()), "\n")
+}
+
+// newBlockedAsyncLogger 返回一个异步日志器，它的 worker 正阻塞在第一条日志 "first" 的写入上
+func newBlockedAsyncLogger(t *testing.T, conf LogConf) (*LogsLogger, *gateWriter) {
+	t.Helper()
+	l, err := NewLogger(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 控制台输出总是同步写入，这里通过输出目标写入 w
+	w := newGateWriter()
+	s, err := NewSink("gate", w, LogLevelDebug, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddSink(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.RemoveSink(LogModeConsole); err != nil {
+		t.Fatal(err)
+	}
+	l.SetLogWriteStrategy(LoggingAsync)
+
+	l.Info("first")
+	select {
+	case <-w.started:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not start writing")
+	}
+	return l, w
+}
+
+func waitQueued(t *testing.T, l *LogsLogger, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for l.AsyncStats().Queued != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("queued = %d, want %d", l.AsyncStats().Queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func queueClosed(q *asyncQueue) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.closed
+}
+
+func assertLines(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines %q, want %q", len(got), got, want)
+	}
+	for i := range want {
+		if !strings.HasSuffix(got[i], want[i]) {
+			t.Fatalf("line %d = %q, want suffix %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDropOldestKeepsSyncMarker(t *testing.T) {
+	l, w := newBlockedAsyncLogger(t, LogConf{QueueSize: 2, OverflowPolicy: OverflowDropOldest})
+
+	synced := make(chan error, 1)
+	go func() { synced <- l.Sync() }()
+	waitQueued(t, l, 1) // 队列中只有刷新标记
+
+	l.Info("second")
+	l.Info("third") // 队列已满：刷新标记放回队尾，丢弃 second
+
+	close(w.gate)
+	select {
+	case err := <-synced:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Sync blocked: flush marker was dropped")
+	}
+
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	assertLines(t, w.lines(), "first", "third")
+	if stats := l.AsyncStats(); stats.Dropped != 1 {
+		t.Fatalf("dropped = %d, want 1", stats.Dropped)
+	}
+}
+
+func TestDropOldestNeverDropErrors(t *testing.T) {
+	l, w := newBlockedAsyncLogger(t, LogConf{QueueSize: 2, OverflowPolicy: OverflowDropOldest, NeverDropErrors: true})
+
+	l.Error("error")
+	l.Info("second")
+	l.Info("third") // 队列已满：error 放回队尾，丢弃 second
+
+	close(w.gate)
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	assertLines(t, w.lines(), "first", "error", "third")
+	if stats := l.AsyncStats(); stats.Dropped != 1 {
+		t.Fatalf("dropped = %d, want 1", stats.Dropped)
+	}
+}
+
+func TestDropOldestBlocksWhenNothingDroppable(t *testing.T) {
+	l, w := newBlockedAsyncLogger(t, LogConf{QueueSize: 1, OverflowPolicy: OverflowDropOldest, NeverDropErrors: true})
+
+	l.Error("error")
+	pushed := make(chan struct{})
+	go func() {
+		l.Info("second") // 队列中只有不能丢弃的日志，阻塞等待
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("push returned while the queue was full of errors")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(w.gate)
+	<-pushed
+
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	assertLines(t, w.lines(), "first", "error", "second")
+}
+
+func TestDropNewest(t *testing.T) {
+	l, w := newBlockedAsyncLogger(t, LogConf{QueueSize: 1, OverflowPolicy: OverflowDropNewest})
+
+	l.Info("second")
+	l.Info("third") // 丢弃
+
+	close(w.gate)
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	assertLines(t, w.lines(), "first", "second")
+	if stats := l.AsyncStats(); stats.Dropped != 1 || stats.Written != 2 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestCloseDrainsQueue(t *testing.T) {
+	l, w := newBlockedAsyncLogger(t, LogConf{QueueSize: 4})
+
+	l.Info("second")
+	l.Info("third")
+	close(w.gate)
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertLines(t, w.lines(), "first", "second", "third")
+
+	// 关闭后同步写入
+	l.Info("fourth")
+	assertLines(t, w.lines(), "first", "second", "third", "fourth")
+}
+
+func TestChildCloseKeepsParentQueue(t *testing.T) {
+	l, w := newBlockedAsyncLogger(t, LogConf{QueueSize: 4})
+	close(w.gate)
+
+	child := l.With("child", true)
+	child.Info("second")
+	if err := child.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// 子日志器关闭前写完了队列中已有的日志，父日志器的队列仍在运行
+	assertLines(t, w.lines(), "first", "second child=true")
+	if !l.queue.matches(l.logConf) {
+		t.Fatal("parent queue closed by child")
+	}
+
+	l.Info("third")
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if stats := l.AsyncStats(); stats.Enqueued != 3 {
+		t.Fatalf("enqueued = %d, want 3", stats.Enqueued)
+	}
+	assertLines(t, w.lines(), "first", "second child=true", "third")
+}
+
+// 重新配置在解锁后才排空旧队列：慢的输出不会让其他日志器的设置和请求中的 With 等待
+func TestSetUpDrainsOutsideLock(t *testing.T) {
+	l, w := newBlockedAsyncLogger(t, LogConf{QueueSize: 4})
+	l.Info("second")
+	q := l.outputs().queue
+	other, err := NewLogger(LogConf{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error)
+	go func() { done <- l.SetUp(LogConf{}) }()
+	deadline := time.Now().Add(time.Second)
+	for !queueClosed(q) {
+		if time.Now().After(deadline) {
+			t.Fatal("old queue not closed by SetUp")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		l.With("request_id", "r1")
+		other.SetEncoding(LogEncodingJSON)
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		close(w.gate)
+		t.Fatal("blocked while SetUp drains the old queue")
+	}
+
+	close(w.gate)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	assertLines(t, w.lines(), "first", "second")
+}
@@ -1,14 +1,122 @@
 package logs
 
-func Close() {
-	// 关闭 shutdownChan，通知 worker 停止接收新日志
-	shutdownOnce.Do(func() {
-		close(shutdownChan)
-	})
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
 
-	// 关闭 logChan, 防止新的日志继续写入
-	close(logChan)
+// CloseError 关闭时在截止时间前没能写完异步队列中的日志
+type CloseError struct {
+	Lost int // 丢失的日志条数
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("logs: close deadline exceeded, %d log entries lost", e.Lost)
+}
+
+// Sync 等待异步队列中已有的日志写入完成，并刷新输出
+func (l *LogsLogger) Sync() error {
+	mu2.Lock()
+	o := l.outputs()
+	sinks := l.sinkSet(o)
+	mu2.Unlock()
+
+	q := o.queue
+	if q != nil {
+		if err := q.flush(context.Background()); err != nil {
+			return err
+		}
+	}
+	if sinks != nil {
+		var errs []error
+		for _, s := range sinks.load() {
+			if s.writer != nil {
+				errs = append(errs, syncOutput(s.writer))
+			}
+		}
+		errs = append(errs, syncOutput(o.output))
+		return errors.Join(errs...)
+	}
+	return syncOutput(o.output)
+}
+
+// Close 停止异步队列并在 ctx 结束前写完其中的日志，然后释放日志文件引用（最后一个引用释放时关闭文件）。
+// With、Named 创建的子日志器只等待共享队列中已有的日志写完，不停止队列，也不释放父日志器的文件，
+// 不需要关闭；父日志器关闭后它们写入文件的日志被丢弃。
+// 可以多次调用；关闭后写入控制台的日志同步写出，写入文件的日志被丢弃。
+// ctx 先结束时返回 *CloseError，其中记录了丢失的日志条数
+func (l *LogsLogger) Close(ctx context.Context) error {
+	mu2.Lock()
+	o := l.outputs()
+	mu2.Unlock()
+
+	q := o.queue
+	lost := 0
+	if q != nil {
+		lost = q.release(l, ctx)
+	}
+
+	syncOutput(o.output)
+
+	// 释放日志文件引用，没有其他日志器使用该文件时关闭它
+	mu2.Lock()
+	l.fileHandle.release()
+	l.fileHandle = nil
+	if ss := l.sinks.Load(); ss != nil && ss.owner == l {
+		for _, s := range ss.load() {
+			syncOutput(s.writer)
+		}
+		ss.release()
+	}
+	mu2.Unlock()
+	waitRotateHooks(ctx)
+
+	if lost > 0 {
+		return &CloseError{Lost: lost}
+	}
+	return nil
+}
+
+// Sync 等待全局日志器异步队列中已有的日志写入完成，并刷新输出
+func Sync() error {
+	return globalLogger.Sync()
+}
+
+// Close 写完所有日志器异步队列中的日志并释放日志文件句柄，可以多次调用
+func Close() error {
+	return CloseContext(context.Background())
+}
+
+// CloseContext 与 Close 相同，但最多等待到 ctx 结束；超时时返回 *CloseError，其中记录了丢失的日志条数
+func CloseContext(ctx context.Context) error {
+	lost := closeAllQueues(ctx)
+
+	syncOutput(globalLogger.output)
+	closeFileWriters()
+	waitRotateHooks(ctx)
+
+	if lost > 0 {
+		return &CloseError{Lost: lost}
+	}
+	return nil
+}
 
-	// 等待 worker 处理完剩余日志
-	wg.Wait()
+// syncOutput 将输出中缓冲的内容刷到磁盘，标准输出/错误流不需要刷新
+func syncOutput(w io.Writer) error {
+	switch out := w.(type) {
+	case *os.File:
+		if isStdStream(out) {
+			return nil
+		}
+		return out.Sync()
+	case *noColorWriter:
+		return syncOutput(out.w)
+	case interface{ Sync() error }:
+		return out.Sync()
+	default:
+		return nil
+	}
 }
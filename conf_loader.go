@@ -0,0 +1,449 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfError 配置校验失败，Field 为出错的配置项（yaml 标签名）
+type ConfError struct {
+	Field  string // 配置项名称，如 "level"、"max_size"
+	Source string // 配置来源：文件路径或环境变量名，校验合并后的配置时为空
+	Err    error
+}
+
+func (e *ConfError) Error() string {
+	if e.Source != "" {
+		return fmt.Sprintf("logs: invalid config field %q (from %s): %v", e.Field, e.Source, e.Err)
+	}
+	return fmt.Sprintf("logs: invalid config field %q: %v", e.Field, e.Err)
+}
+
+func (e *ConfError) Unwrap() error {
+	return e.Err
+}
+
+// LoadConf 按 默认配置 -> 配置文件 -> 环境变量 的顺序合并配置，后者覆盖前者。
+// path 为空时跳过配置文件，envPrefix 为空时跳过环境变量
+func LoadConf(path string, envPrefix string) (LogConf, error) {
+	conf := defaultLogConf
+	if path != "" {
+		if err := applyConfFile(&conf, path); err != nil {
+			return LogConf{}, err
+		}
+	}
+	if envPrefix != "" {
+		if err := applyConfEnv(&conf, envPrefix); err != nil {
+			return LogConf{}, err
+		}
+	}
+	if err := validateConf(conf); err != nil {
+		return LogConf{}, err
+	}
+	return conf, nil
+}
+
+// LoadConfFile 从配置文件加载配置，格式由扩展名决定：.yaml/.yml、.json、.toml，未设置的配置项使用默认值
+func LoadConfFile(path string) (LogConf, error) {
+	return LoadConf(path, "")
+}
+
+// LoadConfFromEnv 从环境变量加载配置，变量名为 前缀_配置项，如 LOGS_LEVEL、LOGS_MODE、LOGS_MAX_SIZE，
+// 未设置（或为空）的变量使用默认值
+func LoadConfFromEnv(prefix string) (LogConf, error) {
+	if prefix == "" {
+		return LogConf{}, fmt.Errorf("env prefix cannot be empty")
+	}
+	return LoadConf("", prefix)
+}
+
+// SetUpFromFile 加载配置（见 LoadConf）并初始化全局日志器
+func SetUpFromFile(path string, envPrefix string) error {
+	conf, err := LoadConf(path, envPrefix)
+	if err != nil {
+		return err
+	}
+	if err := SetUp(conf); err != nil {
+		return err
+	}
+	// SetUp 将级别 0 视为未设置，这里单独设置以保留 debug 级别
+	return SetLogLevel(LogLevel(conf.Level))
+}
+
+// NewLoggerFromFile 加载配置（见 LoadConf）并创建日志器
+func NewLoggerFromFile(path string, envPrefix string) (*LogsLogger, error) {
+	conf, err := LoadConf(path, envPrefix)
+	if err != nil {
+		return nil, err
+	}
+	logger, err := NewLogger(conf)
+	if err != nil {
+		return nil, err
+	}
+	if err := logger.SetLogLevel(LogLevel(conf.Level)); err != nil {
+		return nil, err
+	}
+	return logger, nil
+}
+
+// applyConfFile 解析配置文件并覆盖 conf 中出现的配置项
+func applyConfFile(conf *LogConf, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]interface{})
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("unsupported config file format: %q", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	for key, value := range values {
+		field, ok := confFieldByKey(reflect.TypeOf(*conf), key)
+		if !ok {
+			return &ConfError{Field: key, Source: path, Err: fmt.Errorf("unknown config field")}
+		}
+		if err := setConfField(conf, field, value); err != nil {
+			return &ConfError{Field: confFieldName(field), Source: path, Err: err}
+		}
+	}
+	return nil
+}
+
+// applyConfEnv 用环境变量覆盖 conf 中的配置项
+func applyConfEnv(conf *LogConf, prefix string) error {
+	prefix = strings.TrimSuffix(prefix, "_")
+
+	t := reflect.TypeOf(*conf)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := prefix + "_" + strings.ToUpper(confFieldName(field))
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			continue
+		}
+		if err := setConfField(conf, field, value); err != nil {
+			return &ConfError{Field: confFieldName(field), Source: name, Err: err}
+		}
+	}
+	return nil
+}
+
+// confFieldName 返回配置项名称（yaml 标签名）
+func confFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// confFieldByKey 在结构体 t 中按配置项名称查找字段，忽略大小写以及 "_"、"-"，因此 max_size、maxSize、MaxSize 都可以
+func confFieldByKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(s))
+	}
+
+	key = normalize(key)
+	for i := 0; i < t.NumField(); i++ {
+		if normalize(confFieldName(t.Field(i))) == key {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// setConfField 将配置值转换为字段类型后写入 conf，level、stacktrace_level 和 mode 同时接受名称和数字
+func setConfField(conf *LogConf, field reflect.StructField, value interface{}) error {
+	return setConfValue(reflect.ValueOf(conf).Elem().FieldByIndex(field.Index), confFieldName(field), value)
+}
+
+// setConfValue 将名为 name 的配置值转换后写入 v，LogConf 和 SinkConf 共用
+func setConfValue(v reflect.Value, name string, value interface{}) error {
+	switch name {
+	case "level":
+		level, err := confLevel(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(level))
+		return nil
+	case "stacktrace_level":
+		if s, ok := value.(string); ok && (strings.EqualFold(s, "off") || strings.EqualFold(s, "none")) {
+			v.SetInt(0)
+			return nil
+		}
+		if s, ok := value.(string); ok && strings.EqualFold(strings.TrimSpace(s), "debug") {
+			// 0 表示关闭，debug 无法作为调用栈级别
+			return fmt.Errorf("%q cannot enable stacktraces (0 means off): use off or a level from info to panic", s)
+		}
+		level, err := confLevel(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(level))
+		return nil
+	case "mode":
+		mode, err := confMode(value)
+		if err != nil {
+			return err
+		}
+		v.SetString(mode)
+		return nil
+	case "type":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %T", value)
+		}
+		t, err := sinkType(s)
+		if err != nil {
+			return err
+		}
+		v.SetString(t)
+		return nil
+	case "sinks", "routes":
+		list, err := confList(value, v.Type(), name)
+		if err != nil {
+			return err
+		}
+		v.Set(list)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %T", value)
+		}
+		v.SetString(s)
+	case reflect.Int:
+		n, err := confInt(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		switch b := value.(type) {
+		case bool:
+			v.SetBool(b)
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			if err != nil {
+				return fmt.Errorf("expected bool, got %q", b)
+			}
+			v.SetBool(parsed)
+		default:
+			return fmt.Errorf("expected bool, got %T", value)
+		}
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Kind())
+	}
+	return nil
+}
+
+// confList 将对象列表转换为 sliceType（[]SinkConf 或 []RouteConf），name 用于错误信息。
+// 环境变量中为 JSON 数组，如 [{"type":"file","path":"app.log","encoding":"json"}]
+func confList(value interface{}, sliceType reflect.Type, name string) (reflect.Value, error) {
+	if s, ok := value.(string); ok {
+		var parsed []interface{}
+		if err := json.Unmarshal([]byte(s), &parsed); err != nil {
+			return reflect.Value{}, fmt.Errorf("expected JSON array: %v", err)
+		}
+		value = parsed
+	}
+
+	var items []map[string]interface{}
+	switch list := value.(type) {
+	case []map[string]interface{}: // TOML 的表数组 [[sinks]]
+		items = list
+	case []interface{}:
+		for i, item := range list {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("%s[%d]: expected object, got %T", name, i, item)
+			}
+			items = append(items, m)
+		}
+	default:
+		return reflect.Value{}, fmt.Errorf("expected list of %s, got %T", name, value)
+	}
+
+	list := reflect.MakeSlice(sliceType, len(items), len(items))
+	for i, m := range items {
+		for key, item := range m {
+			field, ok := confFieldByKey(sliceType.Elem(), key)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("%s[%d]: unknown field %q", name, i, key)
+			}
+			v := list.Index(i).FieldByIndex(field.Index)
+			if err := setConfValue(v, confFieldName(field), item); err != nil {
+				return reflect.Value{}, fmt.Errorf("%s[%d].%s: %v", name, i, confFieldName(field), err)
+			}
+		}
+	}
+	return list, nil
+}
+
+// confInt 转换整数配置值，JSON 解析出的 float64 需为整数
+func confInt(value interface{}) (int, error) {
+	switch n := value.(type) {
+	case int:
+		return n, nil
+	case int64:
+		return int(n), nil
+	case uint64:
+		if n > math.MaxInt32 {
+			return 0, fmt.Errorf("value out of range: %d", n)
+		}
+		return int(n), nil
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, fmt.Errorf("expected integer, got %v", n)
+		}
+		return int(n), nil
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil {
+			return 0, fmt.Errorf("expected integer, got %q", n)
+		}
+		return i, nil
+	default:
+		return 0, fmt.Errorf("expected integer, got %T", value)
+	}
+}
+
+// confLevel 转换级别，接受 "debug"/"info"/... 或 0 到 5
+func confLevel(value interface{}) (LogLevel, error) {
+	if s, ok := value.(string); ok {
+		return ParseLogLevel(s)
+	}
+	n, err := confInt(value)
+	if err != nil {
+		return 0, err
+	}
+	if LogLevel(n) < LogLevelDebug || LogLevel(n) > LogLevelPanic {
+		return 0, fmt.Errorf("unknown log level: %d", n)
+	}
+	return LogLevel(n), nil
+}
+
+// confModes 数字形式的输出模式：0 console、1 file、2 both
+var confModes = []string{LogModeConsole, LogModeFile, LogModeBoth}
+
+// confMode 转换输出模式，接受 "console"/"file"/"both" 或 0 到 2
+func confMode(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if n, err := strconv.Atoi(s); err == nil {
+			value = n
+		} else {
+			for _, mode := range confModes {
+				if s == mode {
+					return mode, nil
+				}
+			}
+			return "", fmt.Errorf("unknown log mode: %q", s)
+		}
+	}
+	n, err := confInt(value)
+	if err != nil {
+		return "", err
+	}
+	if n < 0 || n >= len(confModes) {
+		return "", fmt.Errorf("unknown log mode: %d", n)
+	}
+	return confModes[n], nil
+}
+
+// validateConf 校验合并后的配置，错误中给出出错的配置项
+func validateConf(conf LogConf) error {
+	invalid := func(field string, format string, args ...interface{}) error {
+		return &ConfError{Field: field, Err: fmt.Errorf(format, args...)}
+	}
+
+	if _, err := confMode(conf.Mode); err != nil {
+		return invalid("mode", "%v", err)
+	}
+	if len(conf.Sinks) == 0 && (conf.Mode == LogModeFile || conf.Mode == LogModeBoth) && conf.Path == "" {
+		return invalid("path", "log path is required in %s mode", conf.Mode)
+	}
+	if LogLevel(conf.Level) < LogLevelDebug || LogLevel(conf.Level) > LogLevelPanic {
+		return invalid("level", "unknown log level: %d", conf.Level)
+	}
+	if _, err := newEncoder(conf.Encoding); conf.Encoding != "" && err != nil {
+		return invalid("encoding", "%v", err)
+	}
+	if conf.MaxSize < 0 {
+		return invalid("max_size", "must not be negative: %d", conf.MaxSize)
+	}
+	if conf.MaxBackups < 0 {
+		return invalid("max_backups", "must not be negative: %d", conf.MaxBackups)
+	}
+	if conf.KeepDays < 0 {
+		return invalid("keep_days", "must not be negative: %d", conf.KeepDays)
+	}
+	if err := validTimeFormat(conf.TimeFormat); err != nil {
+		return invalid("time_format", "%v", err)
+	}
+	if _, err := loadTimeZone(conf.TimeZone); err != nil {
+		return invalid("time_zone", "%v", err)
+	}
+	if field, err := validateRotation(conf.Rotation, conf.FilePattern, conf.Path); err != nil {
+		return invalid(field, "%v", err)
+	}
+	if conf.QueueSize < 0 {
+		return invalid("queue_size", "must not be negative: %d", conf.QueueSize)
+	}
+	if err := validOverflowPolicy(conf.OverflowPolicy); err != nil {
+		return invalid("overflow_policy", "%v", err)
+	}
+	if LogLevel(conf.StacktraceLevel) < LogLevelDebug || LogLevel(conf.StacktraceLevel) > LogLevelPanic {
+		return invalid("stacktrace_level", "unknown log level: %d", conf.StacktraceLevel)
+	}
+	if _, err := parseNameLevels(conf.NameLevels); err != nil {
+		return invalid("name_levels", "%v", err)
+	}
+	names := make(map[string]bool, len(conf.Sinks))
+	for i, s := range conf.Sinks {
+		if field, err := validateSinkConf(s); err != nil {
+			return invalid(fmt.Sprintf("sinks[%d].%s", i, field), "%v", err)
+		}
+		if field, err := validateSinkRotation(s, conf); err != nil {
+			return invalid(fmt.Sprintf("sinks[%d].%s", i, field), "%v", err)
+		}
+		name := sinkName(s)
+		if names[name] {
+			return invalid(fmt.Sprintf("sinks[%d].name", i), "duplicate sink name: %q", name)
+		}
+		names[name] = true
+	}
+	for i, r := range conf.Routes {
+		if field, err := validateRouteConf(r); err != nil {
+			return invalid(fmt.Sprintf("routes[%d].%s", i, field), "%v", err)
+		}
+		if field, err := validateSinkRotation(r.sinkConf(), conf); err != nil {
+			return invalid(fmt.Sprintf("routes[%d].%s", i, field), "%v", err)
+		}
+	}
+	return nil
+}
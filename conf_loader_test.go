@@ -0,0 +1,165 @@
+package logs
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// 三种配置文件格式加载出相同的配置，名称和数字形式的级别、模式都可以
+func TestLoadConfFormats(t *testing.T) {
+	dir := t.TempDir()
+	want := defaultLogConf
+	want.Mode = LogModeFile
+	want.Level = int(LogLevelWarn)
+	want.Path = "app.log"
+	want.MaxSize = 20
+	want.Compress = true
+	want.TimeFormat = TimeFormatRFC3339
+	want.StacktraceLevel = int(LogLevelError)
+	want.Routes = []RouteConf{{Levels: ">=error", Path: "error.log"}}
+
+	for name, content := range map[string]string{
+		"conf.yaml": `
+mode: file
+level: warn
+path: app.log
+max_size: 20
+compress: true
+time_format: rfc3339
+stacktrace_level: error
+routes:
+  - levels: ">=error"
+    path: error.log
+`,
+		"conf.json": `{"mode": 1, "level": 2, "path": "app.log", "maxSize": 20, "compress": true,
+"time_format": "rfc3339", "stacktrace_level": 3, "routes": [{"levels": ">=error", "path": "error.log"}]}`,
+		"conf.toml": `
+mode = "file"
+level = "WARN"
+path = "app.log"
+max-size = 20
+compress = true
+time_format = "rfc3339"
+stacktrace_level = "error"
+
+[[routes]]
+levels = ">=error"
+path = "error.log"
+`,
+	} {
+		path := filepath.Join(dir, name)
+		writeConfFile(t, path, content)
+		got, err := LoadConfFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s:\n got %+v\nwant %+v", name, got, want)
+		}
+	}
+}
+
+// 优先级：默认配置 < 配置文件 < 环境变量，空的环境变量不覆盖
+func TestLoadConfPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conf.yaml")
+	writeConfFile(t, path, "level: warn\nmax_size: 20\nencoding: json\n")
+	t.Setenv("APP_LOG_LEVEL", "error")
+	t.Setenv("APP_LOG_MAX_BACKUPS", "7")
+	t.Setenv("APP_LOG_ENCODING", "")
+	t.Setenv("APP_LOG_STACKTRACE_LEVEL", "off")
+
+	conf, err := LoadConf(path, "APP_LOG_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		field     string
+		got, want interface{}
+	}{
+		{"level", conf.Level, int(LogLevelError)},             // 环境变量覆盖文件
+		{"max_size", conf.MaxSize, 20},                        // 来自文件
+		{"max_backups", conf.MaxBackups, 7},                   // 来自环境变量
+		{"encoding", conf.Encoding, "json"},                   // 空的环境变量不覆盖
+		{"keep_days", conf.KeepDays, defaultLogConf.KeepDays}, // 默认值
+		{"stacktrace_level", conf.StacktraceLevel, 0},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s = %v, want %v", tc.field, tc.got, tc.want)
+		}
+	}
+}
+
+// 校验失败时错误给出出错的配置项和来源
+func TestLoadConfErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		content string
+		env     map[string]string
+		field   string
+		source  string // "file" 表示配置文件，其他为环境变量名
+	}{
+		{content: "level: verbose\n", field: "level", source: "file"},
+		{content: "max_size: big\n", field: "max_size", source: "file"},
+		{content: "no_such: 1\n", field: "no_such", source: "file"},
+		{content: "stacktrace_level: debug\n", field: "stacktrace_level", source: "file"},
+		{env: map[string]string{"T_STACKTRACE_LEVEL": "debug"}, field: "stacktrace_level", source: "T_STACKTRACE_LEVEL"},
+		{env: map[string]string{"T_COMPRESS": "maybe"}, field: "compress", source: "T_COMPRESS"},
+		{content: "routes:\n  - levels: loud\n    path: e.log\n", field: "routes[0].levels"},
+		{content: "mode: file\n", field: "path"},
+		{content: "time_format: rfc1123z\n", field: "time_format"},
+		{content: "time_format: epoch_s\n", field: "time_format"},
+		{content: "time_format: \"[--:--]\"\n", field: "time_format"},
+		{content: "time_zone: Mars/Base\n", field: "time_zone"},
+		{content: "overflow_policy: spill\n", field: "overflow_policy"},
+	} {
+		path := filepath.Join(dir, "conf.yaml")
+		writeConfFile(t, path, tc.content)
+		for k, v := range tc.env {
+			t.Setenv(k, v)
+		}
+
+		_, err := LoadConf(path, "T")
+		var ce *ConfError
+		if !errors.As(err, &ce) {
+			t.Fatalf("%q %v: err = %v, want *ConfError", tc.content, tc.env, err)
+		}
+		source := tc.source
+		if source == "file" {
+			source = path
+		}
+		if ce.Field != tc.field || ce.Source != source {
+			t.Fatalf("%q %v: field=%q source=%q, want %q %q (%v)", tc.content, tc.env, ce.Field, ce.Source, tc.field, source, err)
+		}
+		for k := range tc.env {
+			t.Setenv(k, "")
+		}
+	}
+}
+
+func TestValidTimeFormat(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		ok     bool
+	}{
+		{"", true},
+		{"rfc3339", true},
+		{"RFC3339Nano", true},
+		{"epoch_ms", true},
+		{"2006-01-02 15:04:05.000", true},
+		{"15:04", true},
+		{"Jan _2 15:04:05", true},
+		{"rfc1123", false},
+		{"unix", false},
+		{"--", false},
+		{"[time]", false},
+	} {
+		if err := validTimeFormat(tc.format); (err == nil) != tc.ok {
+			t.Errorf("validTimeFormat(%q) = %v, want ok=%v", tc.format, err, tc.ok)
+		}
+	}
+	if _, err := NewLogger(LogConf{TimeFormat: "unix"}); err == nil {
+		t.Fatal("NewLogger accepted an unknown time format")
+	}
+}
@@ -55,6 +55,39 @@ func NewLogConfWithDefaults(custom LogConf) LogConf {
 	if custom.Compress {
 		conf.Compress = custom.Compress
 	}
+	if custom.Rotation != "" {
+		conf.Rotation = custom.Rotation
+	}
+	if custom.FilePattern != "" {
+		conf.FilePattern = custom.FilePattern
+	}
+	if custom.TimeFormat != "" {
+		conf.TimeFormat = custom.TimeFormat
+	}
+	if custom.TimeZone != "" {
+		conf.TimeZone = custom.TimeZone
+	}
+	if custom.QueueSize != 0 {
+		conf.QueueSize = custom.QueueSize
+	}
+	if custom.OverflowPolicy != "" {
+		conf.OverflowPolicy = custom.OverflowPolicy
+	}
+	if custom.NeverDropErrors {
+		conf.NeverDropErrors = custom.NeverDropErrors
+	}
+	if custom.NameLevels != "" {
+		conf.NameLevels = custom.NameLevels
+	}
+	if custom.StacktraceLevel != 0 {
+		conf.StacktraceLevel = custom.StacktraceLevel
+	}
+	if len(custom.Sinks) > 0 {
+		conf.Sinks = custom.Sinks
+	}
+	if len(custom.Routes) > 0 {
+		conf.Routes = custom.Routes
+	}
 
 	return conf
 }
@@ -67,7 +100,9 @@ func NewDefaultLogger() *LogsLogger {
 		hasRootFilePrefix: false,
 		logConf:           defaultLogConf,
 		logWriteStrategy:  LoggingSync,
+		rotateHooks:       &rotateHooks{},
 	}
+	logger.level.Store(NewAtomicLevel(LogLevel(defaultLogConf.Level)))
 
 	// 获取项目根目录
 	projectRootOnce.Do(func() {
@@ -111,6 +146,7 @@ func newLogger(writer io.Writer, flag int, prefixFormat string) (*LogsLogger, er
 	logger.errorL = log.New(writer, "ERROR: ", flag)
 	logger.fatalL = log.New(writer, "FATAL: ", flag)
 	logger.panicL = log.New(writer, "PANIC: ", flag)
+	logger.publish()
 
 	return logger, nil
 }
@@ -0,0 +1,294 @@
+package logs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const defaultWatchInterval = 2 * time.Second // 默认轮询间隔
+
+// ConfWatcher 轮询配置文件，文件变化时重新加载配置并应用到日志器。
+// 无效的配置会被拒绝，日志器保持原有配置
+type ConfWatcher struct {
+	target    confTarget
+	logger    *LogsLogger // 用于输出审计日志
+	path      string
+	envPrefix string
+	interval  time.Duration
+
+	mu      sync.Mutex // 串行化重新加载
+	content []byte     // 上次成功加载时的文件内容
+	lastErr error      // 最近一次加载失败的原因，成功后清空
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// confTarget 配置的应用对象，LogsLogger 持有 mu2、全局日志器持有 mu 后调用 reloadConf
+type confTarget interface {
+	// applyConf 应用 conf 相对于 old 的变化，出错时日志器保持原样
+	applyConf(old, conf LogConf, flags int, strategy logWriteStrategy) error
+
+	// confState 返回当前配置以及 SetUp 会重置的标志和写入模式
+	confState() (conf LogConf, flags int, strategy logWriteStrategy)
+}
+
+func (l *LogsLogger) applyConf(old, conf LogConf, flags int, strategy logWriteStrategy) error {
+	mu2.Lock()
+	l.own()
+	r, err := l.reloadConf(old, conf, flags, strategy)
+	mu2.Unlock()
+
+	r.release()
+	return err
+}
+
+func (l *LogsLogger) confState() (LogConf, int, logWriteStrategy) {
+	mu2.Lock()
+	defer mu2.Unlock()
+	l.own()
+	conf := l.logConf
+	conf.Level = int(l.currentLevel())
+	return conf, restoreFlags(l), l.logWriteStrategy
+}
+
+// globalConfTarget 将配置应用到全局日志器
+type globalConfTarget struct{}
+
+func (globalConfTarget) applyConf(old, conf LogConf, flags int, strategy logWriteStrategy) error {
+	mu.Lock()
+	r, err := globalLogger.reloadConf(old, conf, flags, strategy)
+	mu.Unlock()
+
+	r.release()
+	return err
+}
+
+func (globalConfTarget) confState() (LogConf, int, logWriteStrategy) {
+	mu.Lock()
+	defer mu.Unlock()
+	conf := globalLogger.logConf
+	conf.Level = int(globalLogger.currentLevel())
+	return conf, restoreFlags(globalLogger), globalLogger.logWriteStrategy
+}
+
+// restoreFlags 还原 SetFlags 传入的标志（Lrootfile 在设置时被拆分到 hasRootFilePrefix）
+func restoreFlags(l *LogsLogger) int {
+	if l.hasRootFilePrefix {
+		return l.logFlags | Lrootfile
+	}
+	return l.logFlags
+}
+
+// WatchConfFile 加载配置文件（合并顺序见 LoadConf）并应用到日志器，之后每隔 interval 检查一次文件，
+// 内容变化时重新加载。interval <= 0 时使用默认的 2 秒。首次加载失败时返回错误
+func (l *LogsLogger) WatchConfFile(path string, envPrefix string, interval time.Duration) (*ConfWatcher, error) {
+	return watchConfFile(l, l, path, envPrefix, interval)
+}
+
+// WatchConfFile 监听配置文件并应用到全局日志器
+func WatchConfFile(path string, envPrefix string, interval time.Duration) (*ConfWatcher, error) {
+	return watchConfFile(globalConfTarget{}, globalLogger, path, envPrefix, interval)
+}
+
+func watchConfFile(target confTarget, logger *LogsLogger, path string, envPrefix string, interval time.Duration) (*ConfWatcher, error) {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	w := &ConfWatcher{
+		target:    target,
+		logger:    logger,
+		path:      path,
+		envPrefix: envPrefix,
+		interval:  interval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	go w.run()
+	return w, nil
+}
+
+func (w *ConfWatcher) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.Reload()
+		}
+	}
+}
+
+// Reload 立即检查配置文件，内容有变化时重新加载。
+// 配置无效时返回错误并保持原有配置，同一个错误只记录一条 ERROR 日志
+func (w *ConfWatcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	content, err := os.ReadFile(w.path)
+	if err != nil {
+		return w.reject(err)
+	}
+	if w.content != nil && bytes.Equal(content, w.content) {
+		return w.lastErr
+	}
+
+	conf, err := LoadConf(w.path, w.envPrefix)
+	if err != nil {
+		return w.reject(err)
+	}
+
+	old, flags, strategy := w.target.confState()
+	changes := diffConf(old, conf)
+	if err := w.target.applyConf(old, conf, flags, strategy); err != nil {
+		return w.reject(err)
+	}
+
+	w.content = content
+	w.lastErr = nil
+	if len(changes) > 0 {
+		w.audit(changes)
+	}
+	return nil
+}
+
+// Stop 停止监听，不会改变日志器当前的配置，可以多次调用
+func (w *ConfWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+// reject 记录加载失败的原因，连续相同的错误只输出一次
+func (w *ConfWatcher) reject(err error) error {
+	if w.lastErr == nil || w.lastErr.Error() != err.Error() {
+		w.logger.Errorw("logs: config reload rejected, keeping the current config", "path", w.path, "error", err)
+	}
+	w.lastErr = err
+	return err
+}
+
+// audit 输出一条审计日志，列出变化的配置项；不受日志级别限制
+func (w *ConfWatcher) audit(changes []Field) {
+	emitUnfiltered(w.logger, LogLevelInfo, "logs: config reloaded", F("path", w.path), Group("changes", changes...))
+}
+
+// reloadConf 将 conf 相对于 old 的变化应用到日志器：先校验并准备好新的状态（包括打开日志文件），
+// 全部成功后一次性替换，出错时日志器保持原样。输出模式、路径、切割策略、sinks 或 routes 变化时
+// 按 SetUp 重新初始化（保留标志和写入模式），否则只更新变化的配置项；调用方需持有锁，解锁后释放返回的旧输出
+func (l *LogsLogger) reloadConf(old, conf LogConf, flags int, strategy logWriteStrategy) (*retiredOutput, error) {
+	var nameLevels map[string]LogLevel // 为 nil 时名称级别不变
+	if conf.NameLevels != old.NameLevels {
+		parsed, err := parseNameLevels(conf.NameLevels)
+		if err != nil {
+			return nil, err
+		}
+		nameLevels = parsed
+	}
+
+	if conf.Mode != old.Mode || conf.Path != old.Path ||
+		conf.Rotation != old.Rotation || conf.FilePattern != old.FilePattern ||
+		!reflect.DeepEqual(conf.Sinks, old.Sinks) || !reflect.DeepEqual(conf.Routes, old.Routes) {
+		s, err := l.prepareSetUp(conf)
+		if err != nil {
+			return nil, err
+		}
+		s.conf.Level = conf.Level // SetUp 将级别 0 视为未设置，这里保留 debug 级别
+		s.nameLevels = nameLevels
+		s.flags = flags
+		s.strategy = strategy
+		return l.applySetUp(s), nil
+	}
+
+	// 校验并准备变化的配置项
+	if LogLevel(conf.Level) < LogLevelDebug || LogLevel(conf.Level) > LogLevelPanic {
+		return nil, errors.New("invalid log level")
+	}
+	if LogLevel(conf.StacktraceLevel) < LogLevelDebug || LogLevel(conf.StacktraceLevel) > LogLevelPanic {
+		return nil, errors.New("invalid stacktrace level")
+	}
+	if err := validOverflowPolicy(conf.OverflowPolicy); err != nil {
+		return nil, err
+	}
+	encoder := l.encoder
+	if conf.Encoding != old.Encoding {
+		var err error
+		if encoder, err = newEncoder(conf.Encoding); err != nil {
+			return nil, err
+		}
+	}
+	loc := l.timeLocation
+	if conf.TimeZone != old.TimeZone {
+		var err error
+		if loc, err = loadTimeZone(conf.TimeZone); err != nil {
+			return nil, err
+		}
+	}
+
+	// 一次性替换
+	rotationChanged := conf.MaxSize != old.MaxSize || conf.MaxBackups != old.MaxBackups ||
+		conf.KeepDays != old.KeepDays || conf.Compress != old.Compress
+	queueChanged := conf.QueueSize != old.QueueSize || conf.OverflowPolicy != old.OverflowPolicy ||
+		conf.NeverDropErrors != old.NeverDropErrors
+
+	l.logConf = conf
+	l.atomicLevel().SetLevel(LogLevel(conf.Level))
+	l.encoder = encoder
+	l.timeLocation = loc
+	if nameLevels != nil {
+		replaceNameLevels(nameLevels)
+	}
+	if rotationChanged {
+		l.applySinkRotation()
+		if len(conf.Sinks) == 0 {
+			switch conf.Mode {
+			case "file":
+				l.initFileLog(conf.Path)
+			case "both":
+				l.initMultiWriter(conf.Path)
+			}
+		}
+	}
+	var r *retiredOutput
+	if queueChanged && l.queue != nil {
+		r = l.restartQueue()
+	}
+	l.publish()
+	return r, nil
+}
+
+// diffConf 列出变化的配置项，值为 "旧值 -> 新值"
+func diffConf(old, conf LogConf) []Field {
+	var changes []Field
+
+	ov, nv := reflect.ValueOf(old), reflect.ValueOf(conf)
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		a, b := ov.Field(i).Interface(), nv.Field(i).Interface()
+		if reflect.DeepEqual(a, b) {
+			continue
+		}
+		name := confFieldName(t.Field(i))
+		if name == "level" {
+			a, b = LogLevel(old.Level), LogLevel(conf.Level)
+		}
+		changes = append(changes, F(name, fmt.Sprintf("%v -> %v", a, b)))
+	}
+	return changes
+}
@@ -0,0 +1,158 @@
+package logs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 无效的配置在修改日志器之前被拒绝：配置、写入模式和异步队列保持不变，已经打开的文件被释放
+func TestSetUpInvalidKeepsLogger(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	l, err := NewLogger(LogConf{Mode: LogModeFile, Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close(context.Background())
+	l.SetLogWriteStrategy(LoggingAsync)
+	q := l.queue
+
+	routePath := filepath.Join(dir, "error.log")
+	err = l.SetUp(LogConf{
+		Mode:     LogModeFile,
+		Path:     filepath.Join(dir, "new.log"),
+		TimeZone: "No/Such_Zone",
+		Routes:   []RouteConf{{Levels: ">=error", Path: routePath}},
+	})
+	if err == nil {
+		t.Fatal("SetUp accepted an invalid time zone")
+	}
+	if l.logConf.Path != path || l.logWriteStrategy != LoggingAsync || l.queue != q || !q.matches(l.logConf) {
+		t.Fatalf("logger changed by rejected SetUp: path=%q strategy=%v", l.logConf.Path, l.logWriteStrategy)
+	}
+
+	err = l.SetUp(LogConf{
+		Routes: []RouteConf{{Levels: ">=error", Path: routePath}},
+		Sinks:  []SinkConf{{Type: "bad"}},
+	})
+	if err == nil {
+		t.Fatal("SetUp accepted an invalid sink")
+	}
+	if n := fileRefs(t, routePath); n != 0 {
+		t.Fatalf("route file refs = %d, want 0", n)
+	}
+	if n := fileRefs(t, path); n != 1 {
+		t.Fatalf("log file refs = %d, want 1", n)
+	}
+}
+
+func writeConfFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 热加载保留标志和写入模式，输出变化时也不会短暂回到同步写入或默认级别
+func TestWatchConfKeepsFlagsAndStrategy(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "logs.yaml")
+	writeConfFile(t, confPath, "mode: file\nlevel: info\npath: "+filepath.Join(dir, "a.log")+"\n")
+
+	l, err := NewLogger(LogConf{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close(context.Background())
+	w, err := l.WatchConfFile(confPath, "", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if err := l.SetFlags(Ldate | Lshortfile); err != nil {
+		t.Fatal(err)
+	}
+	l.SetLogWriteStrategy(LoggingAsync)
+	flags := restoreFlags(l)
+
+	writeConfFile(t, confPath, "mode: file\nlevel: debug\nqueue_size: 16\npath: "+filepath.Join(dir, "b.log")+"\n")
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if l.logConf.Path != filepath.Join(dir, "b.log") {
+		t.Fatalf("path = %q", l.logConf.Path)
+	}
+	if got := l.currentLevel(); got != LogLevelDebug {
+		t.Fatalf("level = %v, want debug", got)
+	}
+	if restoreFlags(l) != flags || l.logWriteStrategy != LoggingAsync {
+		t.Fatalf("flags = %d, want %d; strategy = %v", restoreFlags(l), flags, l.logWriteStrategy)
+	}
+	if l.queue == nil || cap(l.queue.ch) != 16 {
+		t.Fatal("async queue not rebuilt with the new size")
+	}
+	if n := fileRefs(t, filepath.Join(dir, "a.log")); n != 0 {
+		t.Fatalf("old file refs = %d, want 0", n)
+	}
+}
+
+// 热加载与写入并发：每条日志完整地使用加载前或加载后的编码和时间格式
+func TestReloadWhileLogging(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "logs.yaml")
+	logPath := filepath.Join(dir, "app.log")
+	confs := []string{
+		"mode: file\npath: " + logPath + "\nencoding: plain\n",
+		"mode: file\npath: " + logPath + "\nencoding: json\ntime_format: rfc3339\ntime_zone: UTC\n",
+	}
+	writeConfFile(t, confPath, confs[0])
+
+	l, err := NewLogger(LogConf{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close(context.Background())
+	w, err := l.WatchConfFile(confPath, "", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	child := l.With("child", true)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, logger := range []*LogsLogger{l, child} {
+		wg.Add(1)
+		go func(logger *LogsLogger) {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					logger.Infow("tick", "n", 1)
+				}
+			}
+		}(logger)
+	}
+	for i := 0; i < 20; i++ {
+		writeConfFile(t, confPath, confs[(i+1)%2])
+		if err := w.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	for _, line := range strings.Split(strings.TrimSpace(readFile(t, logPath)), "\n") {
+		json := strings.HasPrefix(line, "{") && strings.HasSuffix(line, "}")
+		if !json && !strings.Contains(line, "[INFO] ") {
+			t.Fatalf("mixed encoding in line %q", line)
+		}
+	}
+}
@@ -3,21 +3,38 @@ package logs
 import (
 	"io"
 	"log"
+	"log/slog"
 	"os"
 	"sync"
-
-	"gopkg.in/natefinch/lumberjack.v2"
+	"sync/atomic"
+	"time"
 )
 
 type LogConf struct {
 	Mode       string `yaml:"mode"`        // 日志输出模式：console/file
 	Level      int    `yaml:"level"`       // 日志级别：debug/info/warn/error/fatal/panic
-	Encoding   string `yaml:"encoding"`    // 日志编码：plain/json
+	Encoding   string `yaml:"encoding"`    // 日志编码：plain/json/logfmt/console
 	Path       string `yaml:"path"`        // 日志文件路径（仅在文件模式下使用）
 	MaxSize    int    `yaml:"max_size"`    // 日志文件最大大小（MB）
 	MaxBackups int    `yaml:"max_backups"` // 日志文件最大保留数量
 	KeepDays   int    `yaml:"keep_days"`   // 日志文件保留天数（仅在文件模式下使用）
 	Compress   bool   `yaml:"compress"`    // 是否压缩日志文件（仅在文件模式下使用）
+
+	Rotation    string `yaml:"rotation"`     // 切割策略：size/hourly/daily/hourly+size/daily+size，默认 size
+	FilePattern string `yaml:"file_pattern"` // 按时间切割时的文件名，如 app-%Y%m%d.log，Path 为指向当前文件的符号链接
+
+	TimeFormat string `yaml:"time_format"` // 时间格式：Go 时间布局或预设 rfc3339/rfc3339nano/iso8601/epoch/epoch_ms/epoch_us/epoch_ns，为空时使用 Ldate、Ltime 等标志
+	TimeZone   string `yaml:"time_zone"`   // 时区：Local/UTC/Asia/Shanghai 等，为空时使用本地时区
+
+	QueueSize       int    `yaml:"queue_size"`        // 异步队列长度（仅在异步模式下使用），默认 1000
+	OverflowPolicy  string `yaml:"overflow_policy"`   // 异步队列写满时的策略：block/drop_newest/drop_oldest/sync，默认 block
+	NeverDropErrors bool   `yaml:"never_drop_errors"` // 队列写满时 Error 及以上级别的日志也不丢弃
+
+	NameLevels      string `yaml:"name_levels"`      // 具名日志器按名称前缀设置的级别，如 "db=debug,http=warn"
+	StacktraceLevel int    `yaml:"stacktrace_level"` // 达到该级别的日志附加调用栈，如 3（error），0 表示不附加
+
+	Sinks  []SinkConf  `yaml:"sinks"`  // 输出目标列表，每个可以有独立的级别、编码和输出；设置后 Mode 不再生效
+	Routes []RouteConf `yaml:"routes"` // 按级别范围额外写入的日志文件，如 ">=error" 写入 error.log
 }
 
 type LogLevel int
@@ -33,16 +50,28 @@ type LogsLogger struct { // 包含所有日志器的结构体
 	hasRootFilePrefix bool // 是否打印自定义的相对路径前缀
 	output            io.Writer
 	logFlags          int
-	encoder           Encoder          // 编码器
-	logConf           LogConf          // 日志配置
-	logWriteStrategy  logWriteStrategy // 默认日志模式为同步模式
+	encoder           RecordEncoder               // 编码器
+	logConf           LogConf                     // 日志配置
+	logWriteStrategy  logWriteStrategy            // 默认日志模式为同步模式
+	fields            []Field                     // 附加到每条日志的结构化字段
+	handler           slog.Handler                // 不为空时，日志交给该 slog.Handler 输出
+	timeLocation      *time.Location              // 日志时间所用的时区
+	queue             *asyncQueue                 // 异步写入队列，启用异步模式时创建
+	fileHandle        *fileHandle                 // 日志文件写入器（file/both 模式下使用）
+	level             atomic.Pointer[AtomicLevel] // 日志级别，热路径上无锁读取，可以在日志器之间共享
+	named             *loggerName                 // 日志器名称，由 Named 设置
+	sinks             atomic.Pointer[sinkSet]     // 自己的输出目标，为空时使用快照中的输出目标
+	rotateHooks       *rotateHooks                // 日志文件切割后调用的钩子，与子日志器共享
+	out               atomic.Pointer[outputCell]  // 写入时使用的配置快照，与子日志器共享
 }
 
 type logItem struct {
-	logger *LogsLogger
-	level  LogLevel
-	msg    string
-	skip   int
+	out   *loggerOutput // 入队时的日志器配置快照，配置变化后旧的日志仍写入旧的输出
+	level LogLevel
+	line  string // 编码后的整行日志
+	sink  *Sink  // 不为空时写入该输出目标，否则写入日志器按级别对应的输出
+
+	flushed chan struct{} // 不为空时表示这是 Sync 的刷新标记，worker 处理到它时关闭该通道
 }
 
 type logWriteStrategy int
@@ -57,8 +86,10 @@ const (
 )
 
 const (
-	LogEncodingPlain = "plain" // 纯文本编码
-	LogEncodingJSON  = "json"  // JSON 编码
+	LogEncodingPlain   = "plain"   // 纯文本编码
+	LogEncodingJSON    = "json"    // JSON 编码
+	LogEncodingLogfmt  = "logfmt"  // logfmt 编码（key=value）
+	LogEncodingConsole = "console" // 彩色控制台编码，用于本地开发
 
 	LogModeConsole = "console" // 输出到控制台
 	LogModeFile    = "file"    // 输出到文件
@@ -114,19 +145,14 @@ var (
 
 	globalLogger = &LogsLogger{} // 全局日志器实例
 
-	fileLogger *lumberjack.Logger // 用于文件输出的日志器
-
 	projectRoot     string
 	mu              sync.Mutex // globalLogger 用于保护日志器的互斥锁
 	mu2             sync.Mutex // LogsLogger 用于保护日志器的互斥锁
 	projectRootOnce sync.Once
 
+	writeMu sync.Mutex // 串行化写入
+
 	defaultLogChanSize = 1000 // 设置足够大，应对大部分情况
-	logChan         chan logItem
-	initWorkerOnce  sync.Once
-	shutdownOnce   	sync.Once
-	shutdownChan   	chan struct{}
-	wg 				sync.WaitGroup
 )
 
 /*
@@ -0,0 +1,181 @@
+package logs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// ANSI 颜色
+const (
+	colorReset   = "\x1b[0m"
+	colorBold    = "\x1b[1m"
+	colorDim     = "\x1b[2m"
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorBlue    = "\x1b[34m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+)
+
+const (
+	consoleCallerWidth  = 24 // 调用者列的最小宽度
+	consoleMessageWidth = 40 // 有字段时消息列的最小宽度
+)
+
+// ConsoleEncoder 面向本地开发的控制台编码器：彩色级别标签、对齐的列、暗色的调用者路径
+type ConsoleEncoder struct {
+	Color bool // 是否输出 ANSI 颜色
+}
+
+// NewConsoleEncoder 创建控制台编码器，标准输出不是终端或设置了 NO_COLOR 时自动关闭颜色
+func NewConsoleEncoder() *ConsoleEncoder {
+	return &ConsoleEncoder{Color: colorEnabled()}
+}
+
+func (e *ConsoleEncoder) EncodeRecord(r *Record) string {
+	var sb strings.Builder
+
+	if r.TimeLayout != "" {
+		e.writeColored(&sb, colorDim, formatTime(recordTime(r), r.TimeLayout))
+		sb.WriteByte(' ')
+	} else if r.Flags&(Ldate|Ltime|Lmicroseconds) != 0 {
+		t := recordTime(r)
+		layout := "15:04:05"
+		if r.Flags&Lmicroseconds != 0 {
+			layout = "15:04:05.000000"
+		}
+		if r.Flags&Ldate != 0 {
+			layout = "2006/01/02 " + layout
+		}
+		e.writeColored(&sb, colorDim, t.Format(layout))
+		sb.WriteByte(' ')
+	}
+
+	tag := strings.TrimSpace(levelTag(r.Level))
+	e.writeColored(&sb, levelColor(r.Level), tag)
+	sb.WriteString(strings.Repeat(" ", len("[DEBUG]")-len(tag)+1))
+
+	if prefix := r.customPrefix(); prefix != "" {
+		sb.WriteString(prefix + " ")
+	}
+	if r.Caller.Defined() {
+		caller := r.Caller.String()
+		e.writeColored(&sb, colorDim, caller)
+		sb.WriteString(padding(caller, consoleCallerWidth))
+		sb.WriteByte(' ')
+	}
+	if r.LoggerName != "" {
+		e.writeColored(&sb, colorBold, r.LoggerName)
+		sb.WriteByte(' ')
+	}
+
+	sb.WriteString(r.Message)
+	if fields := append(r.Fields[:len(r.Fields):len(r.Fields)], errorFields(r.Err)...); len(fields) > 0 {
+		sb.WriteString(padding(r.Message, consoleMessageWidth))
+		e.writeFields(&sb, "", fields)
+	}
+	if r.Stack != "" {
+		var stack strings.Builder
+		appendPlainStack(&stack, r.Stack)
+		e.writeColored(&sb, colorDim, stack.String())
+	}
+	return sb.String()
+}
+
+func (e *ConsoleEncoder) writeFields(sb *strings.Builder, prefix string, fields []Field) {
+	for _, f := range fields {
+		if group, ok := f.Value.([]Field); ok {
+			e.writeFields(sb, prefix+f.Key+".", group)
+			continue
+		}
+		sb.WriteString("  ")
+		e.writeColored(sb, colorCyan, prefix+f.Key+"=")
+		sb.WriteString(plainValue(f.Value))
+	}
+}
+
+func (e *ConsoleEncoder) writeColored(sb *strings.Builder, color, s string) {
+	if !e.Color {
+		sb.WriteString(s)
+		return
+	}
+	sb.WriteString(color)
+	sb.WriteString(s)
+	sb.WriteString(colorReset)
+}
+
+func levelColor(level LogLevel) string {
+	switch level {
+	case LogLevelDebug:
+		return colorBlue
+	case LogLevelInfo:
+		return colorGreen
+	case LogLevelWarn:
+		return colorYellow
+	case LogLevelError:
+		return colorRed
+	default:
+		return colorBold + colorMagenta
+	}
+}
+
+// padding 返回将 s 补齐到 width 所需的空格
+func padding(s string, width int) string {
+	n := width - utf8.RuneCountInString(s)
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat(" ", n)
+}
+
+// colorEnabled 标准输出是终端且未设置 NO_COLOR 时返回 true
+func colorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// noColorWriter 去除写入内容中的 ANSI 转义序列，保证日志文件中不出现颜色代码
+type noColorWriter struct {
+	w io.Writer
+}
+
+func (w *noColorWriter) Write(p []byte) (int, error) {
+	if bytes.IndexByte(p, '\x1b') < 0 {
+		return w.w.Write(p)
+	}
+	if _, err := w.w.Write(stripANSI(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// stripANSI 去除 CSI 转义序列（ESC [ ... 结束字符）
+func stripANSI(p []byte) []byte {
+	out := make([]byte, 0, len(p))
+	for i := 0; i < len(p); i++ {
+		if p[i] != '\x1b' || i+1 >= len(p) || p[i+1] != '[' {
+			out = append(out, p[i])
+			continue
+		}
+		j := i + 2
+		for j < len(p) && (p[j] < 0x40 || p[j] > 0x7e) {
+			j++
+		}
+		i = j
+	}
+	return out
+}
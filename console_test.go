@@ -0,0 +1,96 @@
+package logs
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestConsoleEncoderRecord(t *testing.T) {
+	const ts = "2024-01-02T03:04:05Z "
+	for _, tc := range []struct {
+		name  string
+		color bool
+		edit  func(r *Record)
+		want  string
+	}{
+		{
+			"columns",
+			false,
+			func(r *Record) { r.Caller = Caller{File: "/abs/x.go", Line: 3} },
+			ts + "[INFO]  /abs/x.go:3" + strings.Repeat(" ", 24-len("/abs/x.go:3")) + " m",
+		},
+		{
+			"debug tag",
+			false,
+			func(r *Record) { r.Level, r.Prefix = LogLevelDebug, levelTag(LogLevelDebug) },
+			ts + "[DEBUG] m",
+		},
+		{
+			"fields",
+			false,
+			func(r *Record) {
+				r.LoggerName = "db"
+				r.Fields = []Field{F("k", "a b"), Group("g", F("n", 1))}
+			},
+			ts + "[INFO]  db m" + strings.Repeat(" ", 39) + `  k="a b"  g.n=1`,
+		},
+		{
+			"colors",
+			true,
+			func(r *Record) {
+				r.Level, r.Prefix = LogLevelError, levelTag(LogLevelError)
+				r.Caller = Caller{File: "/abs/x.go", Line: 3}
+				r.Fields = []Field{F("k", "v")}
+			},
+			"\x1b[2m2024-01-02T03:04:05Z\x1b[0m \x1b[31m[ERROR]\x1b[0m \x1b[2m/abs/x.go:3\x1b[0m" + strings.Repeat(" ", 24-len("/abs/x.go:3")) +
+				" m" + strings.Repeat(" ", 39) + "  \x1b[36mk=\x1b[0mv",
+		},
+		{
+			"stack",
+			true,
+			func(r *Record) {
+				r.TimeLayout = ""
+				r.Stack = "main.f\n\t/a.go:1"
+			},
+			"\x1b[32m[INFO]\x1b[0m  m\x1b[2m\n\tmain.f\n\t\t/a.go:1\x1b[0m",
+		},
+	} {
+		r := testRecord("m")
+		tc.edit(r)
+		if got := (&ConsoleEncoder{Color: tc.color}).EncodeRecord(r); got != tc.want {
+			t.Errorf("%s:\n got %q\nwant %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestConsoleColorDetection(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if NewConsoleEncoder().Color {
+		t.Fatal("color enabled with NO_COLOR set")
+	}
+	t.Setenv("NO_COLOR", "")
+	if NewConsoleEncoder().Color != isTerminal(os.Stdout) {
+		t.Fatal("color does not follow whether stdout is a terminal")
+	}
+}
+
+// 写入文件的内容去掉颜色代码
+func TestNoColorWriter(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"\x1b[31m[ERROR]\x1b[0m m", "[ERROR] m"},
+		{"\x1b[1m\x1b[35mbold\x1b[0m", "bold"},
+		{"\x1b[2", ""},       // 截断的转义序列
+		{"a\x1bb", "a\x1bb"}, // 不是 CSI 序列
+	} {
+		var buf bytes.Buffer
+		n, err := (&noColorWriter{w: &buf}).Write([]byte(tc.in))
+		if err != nil || n != len(tc.in) || buf.String() != tc.want {
+			t.Errorf("Write(%q) = %d, %v; wrote %q, want %q", tc.in, n, err, buf.String(), tc.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type ctxLoggerKey struct{}
+
+// ContextExtractor 从 context 中提取需要附加到日志的字段，如请求 ID、租户 ID
+type ContextExtractor func(ctx context.Context) []Field
+
+var (
+	extractorMu sync.RWMutex
+	extractors  []ContextExtractor
+)
+
+// NewContext 返回携带 logger 的 context
+func NewContext(ctx context.Context, logger *LogsLogger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, ctxLoggerKey{}, logger)
+}
+
+// FromContext 取出 context 中的日志器，没有时返回全局日志器
+func FromContext(ctx context.Context) *LogsLogger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(ctxLoggerKey{}).(*LogsLogger); ok && logger != nil {
+			return logger
+		}
+	}
+	return globalLogger
+}
+
+// RegisterContextExtractor 注册字段提取函数，所有 XxxCtx 方法都会调用已注册的提取函数
+func RegisterContextExtractor(extractor ContextExtractor) {
+	if extractor == nil {
+		return
+	}
+	extractorMu.Lock()
+	defer extractorMu.Unlock()
+	extractors = append(extractors, extractor)
+}
+
+// ContextValue 返回一个提取函数：ctx.Value(key) 存在时，以 name 为键输出
+//
+//	logs.RegisterContextExtractor(logs.ContextValue(requestIDKey{}, "request_id"))
+func ContextValue(key interface{}, name string) ContextExtractor {
+	return func(ctx context.Context) []Field {
+		if v := ctx.Value(key); v != nil {
+			return []Field{{Key: name, Value: v}}
+		}
+		return nil
+	}
+}
+
+// contextFields 调用所有提取函数，收集 context 中的字段
+func contextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+
+	extractorMu.RLock()
+	defer extractorMu.RUnlock()
+
+	var fields []Field
+	for _, extract := range extractors {
+		fields = append(fields, extract(ctx)...)
+	}
+	return fields
+}
+
+// LogsLogger 的 context 输出方法 -------------------------------------------------------
+func (l *LogsLogger) DebugCtx(ctx context.Context, v ...interface{}) {
+	outputLogCtx(ctx, l, LogLevelDebug, 3, "", v)
+}
+func (l *LogsLogger) DebugfCtx(ctx context.Context, format string, v ...interface{}) {
+	outputLogCtx(ctx, l, LogLevelDebug, 3, format, v)
+}
+
+func (l *LogsLogger) InfoCtx(ctx context.Context, v ...interface{}) {
+	outputLogCtx(ctx, l, LogLevelInfo, 3, "", v)
+}
+func (l *LogsLogger) InfofCtx(ctx context.Context, format string, v ...interface{}) {
+	outputLogCtx(ctx, l, LogLevelInfo, 3, format, v)
+}
+
+func (l *LogsLogger) WarnCtx(ctx context.Context, v ...interface{}) {
+	outputLogCtx(ctx, l, LogLevelWarn, 3, "", v)
+}
+func (l *LogsLogger) WarnfCtx(ctx context.Context, format string, v ...interface{}) {
+	outputLogCtx(ctx, l, LogLevelWarn, 3, format, v)
+}
+
+func (l *LogsLogger) ErrorCtx(ctx context.Context, v ...interface{}) {
+	outputLogCtx(ctx, l, LogLevelError, 3, "", v)
+}
+func (l *LogsLogger) ErrorfCtx(ctx context.Context, format string, v ...interface{}) {
+	outputLogCtx(ctx, l, LogLevelError, 3, format, v)
+}
+
+func (l *LogsLogger) FatalCtx(ctx context.Context, v ...interface{}) {
+	outputLogCtx(ctx, l, LogLevelFatal, 3, "", v)
+	exitAfterFlush(1)
+}
+func (l *LogsLogger) FatalfCtx(ctx context.Context, format string, v ...interface{}) {
+	outputLogCtx(ctx, l, LogLevelFatal, 3, format, v)
+	exitAfterFlush(1)
+}
+
+func (l *LogsLogger) PanicCtx(ctx context.Context, v ...interface{}) {
+	outputLogCtx(ctx, l, LogLevelPanic, 3, "", v)
+	flushQueues()
+	panic(fmt.Sprint(v...))
+}
+func (l *LogsLogger) PanicfCtx(ctx context.Context, format string, v ...interface{}) {
+	outputLogCtx(ctx, l, LogLevelPanic, 3, format, v)
+	flushQueues()
+	panic(fmt.Sprintf(format, v...))
+}
+
+// 包级别的 context 输出方法，使用 context 中的日志器，没有时使用全局日志器 ---------------------
+func DebugCtx(ctx context.Context, v ...interface{}) {
+	outputLogCtx(ctx, FromContext(ctx), LogLevelDebug, 3, "", v)
+}
+func DebugfCtx(ctx context.Context, format string, v ...interface{}) {
+	outputLogCtx(ctx, FromContext(ctx), LogLevelDebug, 3, format, v)
+}
+
+func InfoCtx(ctx context.Context, v ...interface{}) {
+	outputLogCtx(ctx, FromContext(ctx), LogLevelInfo, 3, "", v)
+}
+func InfofCtx(ctx context.Context, format string, v ...interface{}) {
+	outputLogCtx(ctx, FromContext(ctx), LogLevelInfo, 3, format, v)
+}
+
+func WarnCtx(ctx context.Context, v ...interface{}) {
+	outputLogCtx(ctx, FromContext(ctx), LogLevelWarn, 3, "", v)
+}
+func WarnfCtx(ctx context.Context, format string, v ...interface{}) {
+	outputLogCtx(ctx, FromContext(ctx), LogLevelWarn, 3, format, v)
+}
+
+func ErrorCtx(ctx context.Context, v ...interface{}) {
+	outputLogCtx(ctx, FromContext(ctx), LogLevelError, 3, "", v)
+}
+func ErrorfCtx(ctx context.Context, format string, v ...interface{}) {
+	outputLogCtx(ctx, FromContext(ctx), LogLevelError, 3, format, v)
+}
+
+func FatalCtx(ctx context.Context, v ...interface{}) {
+	outputLogCtx(ctx, FromContext(ctx), LogLevelFatal, 3, "", v)
+	exitAfterFlush(1)
+}
+func FatalfCtx(ctx context.Context, format string, v ...interface{}) {
+	outputLogCtx(ctx, FromContext(ctx), LogLevelFatal, 3, format, v)
+	exitAfterFlush(1)
+}
+
+func PanicCtx(ctx context.Context, v ...interface{}) {
+	outputLogCtx(ctx, FromContext(ctx), LogLevelPanic, 3, "", v)
+	flushQueues()
+	panic(fmt.Sprint(v...))
+}
+func PanicfCtx(ctx context.Context, format string, v ...interface{}) {
+	outputLogCtx(ctx, FromContext(ctx), LogLevelPanic, 3, format, v)
+	flushQueues()
+	panic(fmt.Sprintf(format, v...))
+}
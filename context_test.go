@@ -0,0 +1,56 @@
+package logs
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type countedKey struct{}
+
+// 级别不满足时不调用提取函数；输出时调用者仍是调用 XxxCtx 的位置
+func TestContextFieldsAfterLevelCheck(t *testing.T) {
+	extractorMu.RLock()
+	saved := extractors
+	extractorMu.RUnlock()
+	t.Cleanup(func() {
+		extractorMu.Lock()
+		extractors = saved
+		extractorMu.Unlock()
+	})
+
+	var calls atomic.Int32
+	RegisterContextExtractor(func(ctx context.Context) []Field {
+		if v := ctx.Value(countedKey{}); v != nil {
+			calls.Add(1)
+			return []Field{{Key: "tenant", Value: v}}
+		}
+		return nil
+	})
+
+	l, buf := newBufferLogger(t)
+	if err := l.SetFlags(Lshortfile); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetLogLevel(LogLevelInfo); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), countedKey{}, "t1")
+
+	l.DebugCtx(ctx, "dropped")
+	DebugCtx(NewContext(ctx, l), "dropped")
+	if n := calls.Load(); n != 0 {
+		t.Fatalf("extractor called %d times for disabled level", n)
+	}
+
+	l.InfoCtx(ctx, "kept")
+	InfofCtx(NewContext(ctx, l), "%s", "kept")
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("extractor called %d times, want 2", n)
+	}
+	got := buf.String()
+	if strings.Contains(got, "dropped") || strings.Count(got, "tenant=t1") != 2 || strings.Count(got, "context_test.go ") != 2 {
+		t.Fatalf("output = %q", got)
+	}
+}
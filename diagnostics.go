@@ -0,0 +1,47 @@
+package logs
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Diagnostic 日志库自身的诊断信息，如清理删除了哪些文件、切割钩子最终失败
+type Diagnostic struct {
+	Time    time.Time
+	Source  string // 来源：retention、rotate_hook
+	Message string
+	Path    string // 相关的文件
+	Size    int64  // 删除的文件大小（retention）
+	Err     error
+}
+
+// diagnosticsBufferSize 诊断通道的缓冲大小，写满时丢弃新的诊断信息
+const diagnosticsBufferSize = 256
+
+var (
+	diagnostics        = make(chan Diagnostic, diagnosticsBufferSize)
+	droppedDiagnostics atomic.Int64
+)
+
+// Diagnostics 返回日志库的诊断通道。诊断信息不会写入日志（避免日志写入失败时循环），
+// 需要时由调用方读取并处理；没有读取时最多缓冲 256 条，之后的会被丢弃
+func Diagnostics() <-chan Diagnostic {
+	return diagnostics
+}
+
+// DroppedDiagnostics 返回因诊断通道写满而丢弃的条数
+func DroppedDiagnostics() int64 {
+	return droppedDiagnostics.Load()
+}
+
+// reportDiagnostic 发送诊断信息，不阻塞
+func reportDiagnostic(d Diagnostic) {
+	if d.Time.IsZero() {
+		d.Time = time.Now()
+	}
+	select {
+	case diagnostics <- d:
+	default:
+		droppedDiagnostics.Add(1)
+	}
+}
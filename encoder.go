@@ -1,26 +1,282 @@
 package logs
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
+// newEncoder 根据编码名称创建编码器
+func newEncoder(encoding string) (RecordEncoder, error) {
+	switch encoding {
+	case LogEncodingPlain:
+		return &PlainEncoder{}, nil
+	case LogEncodingJSON:
+		return &JsonEncoder{}, nil
+	case LogEncodingLogfmt:
+		return &LogfmtEncoder{}, nil
+	case LogEncodingConsole:
+		return NewConsoleEncoder(), nil
+	default:
+		return nil, fmt.Errorf("unsupported log encoding: %s", encoding)
+	}
+}
+
+// formatRecordTime 按记录的时间布局输出时间；未设置时使用 RFC3339，设置了 Lmicroseconds 时精确到微秒
+func formatRecordTime(r *Record) string {
+	t := recordTime(r)
+	if r.TimeLayout != "" {
+		return formatTime(t, r.TimeLayout)
+	}
+	if r.Flags&Lmicroseconds != 0 {
+		return t.Format("2006-01-02T15:04:05.000000Z07:00")
+	}
+	return t.Format(time.RFC3339)
+}
+
+// Encoder 旧的编码器接口，只能看到消息参数，可通过 AdaptEncoder 转换为 RecordEncoder
 type Encoder interface {
 	Encode(v ...interface{}) string
 }
 
+// FieldEncoder 是可选接口，实现后编码器可以自行渲染结构化字段
+type FieldEncoder interface {
+	EncodeWithFields(fields []Field, v ...interface{}) string
+}
+
+// PlainEncoder 纯文本编码器：前缀 时间 调用者: 消息 key=value
 type PlainEncoder struct{}
 
+func (e *PlainEncoder) EncodeRecord(r *Record) string {
+	var sb strings.Builder
+	appendPlainHeader(&sb, r)
+	sb.WriteString(r.Message)
+	appendPlainFields(&sb, r.Fields)
+	appendPlainFields(&sb, errorFields(r.Err)) // 错误信息已在消息中，只追加类型、原因链和错误字段
+	appendPlainStack(&sb, r.Stack)
+	return sb.String()
+}
+
 func (e *PlainEncoder) Encode(v ...interface{}) string {
 	return fmt.Sprint(v...)
 }
 
+// EncodeWithFields 输出 "消息 key=value key2=value2"
+func (e *PlainEncoder) EncodeWithFields(fields []Field, v ...interface{}) string {
+	var sb strings.Builder
+	sb.WriteString(e.Encode(v...))
+	appendPlainFields(&sb, fields)
+	return sb.String()
+}
+
+// JsonEncoder JSON 编码器，每条日志输出为一个完整的 JSON 对象
 type JsonEncoder struct{}
 
+func (e *JsonEncoder) EncodeRecord(r *Record) string {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	if n, ok := epochTime(recordTime(r), r.TimeLayout); ok {
+		appendJSONMember(&buf, "timestamp", n) // epoch 时间戳输出为数字
+	} else {
+		appendJSONMember(&buf, "timestamp", formatRecordTime(r))
+	}
+	buf.WriteByte(',')
+	appendJSONMember(&buf, "level", r.Level.String())
+	if prefix := r.customPrefix(); prefix != "" {
+		buf.WriteByte(',')
+		appendJSONMember(&buf, "prefix", prefix)
+	}
+	if r.Caller.Defined() {
+		buf.WriteByte(',')
+		appendJSONMember(&buf, "file", r.Caller.String())
+	}
+	if r.LoggerName != "" {
+		buf.WriteByte(',')
+		appendJSONMember(&buf, "logger", r.LoggerName)
+	}
+	buf.WriteByte(',')
+	appendJSONMember(&buf, "message", r.Message)
+	if r.Err != nil && !hasErrorField(r.Fields) {
+		buf.WriteByte(',')
+		appendJSONMember(&buf, "error", r.Err.Error())
+	}
+	for _, f := range r.Fields {
+		buf.WriteByte(',')
+		appendJSONMember(&buf, f.Key, f.Value)
+	}
+	for _, f := range errorFields(r.Err) {
+		buf.WriteByte(',')
+		appendJSONMember(&buf, f.Key, f.Value)
+	}
+	if r.Stack != "" {
+		buf.WriteByte(',')
+		appendJSONMember(&buf, "stacktrace", r.Stack)
+	}
+
+	buf.WriteByte('}')
+	return buf.String()
+}
+
 func (e *JsonEncoder) Encode(v ...interface{}) string {
-	b, err := json.Marshal(v)
+	values := make([]interface{}, len(v))
+	for i, a := range v {
+		values[i] = a
+		if err, ok := a.(error); ok {
+			if _, ok := a.(json.Marshaler); !ok {
+				values[i] = err.Error() // 错误类型直接序列化会得到 {}
+			}
+		}
+	}
+	b, err := json.Marshal(values)
 	if err != nil {
 		return fmt.Sprintf("JSON marshal error: %v", err)
 	}
 	return string(b)
 }
+
+// EncodeWithFields 输出 {"message":"消息","key":value,...}，字段作为对象成员按顺序输出
+func (e *JsonEncoder) EncodeWithFields(fields []Field, v ...interface{}) string {
+	if len(fields) == 0 {
+		return e.Encode(v...)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	appendJSONMember(&buf, "message", fmt.Sprint(v...))
+	for _, f := range fields {
+		buf.WriteByte(',')
+		appendJSONMember(&buf, f.Key, f.Value)
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+// appendJSONMember 追加一个 "key":value 对象成员
+func appendJSONMember(buf *bytes.Buffer, key string, value interface{}) {
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+
+	// 错误类型通常没有导出字段，直接序列化会得到 {}，因此输出 err.Error()
+	if err, ok := value.(error); ok {
+		if _, ok := value.(json.Marshaler); !ok {
+			value = err.Error()
+		}
+	}
+
+	if group, ok := value.([]Field); ok {
+		buf.WriteByte('{')
+		for i, f := range group {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			appendJSONMember(buf, f.Key, f.Value)
+		}
+		buf.WriteByte('}')
+		return
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprintf("JSON marshal error: %v", err))
+	}
+	buf.Write(b)
+}
+
+// encodeMessage 使用编码器生成消息文本，并在有字段时渲染字段
+func encodeMessage(encoder Encoder, fields []Field, v ...interface{}) string {
+	if len(fields) == 0 {
+		return encoder.Encode(v...)
+	}
+	if fe, ok := encoder.(FieldEncoder); ok {
+		return fe.EncodeWithFields(fields, v...)
+	}
+
+	// 自定义编码器不支持字段时，以 key=value 的形式追加
+	var sb strings.Builder
+	sb.WriteString(encoder.Encode(v...))
+	appendPlainFields(&sb, fields)
+	return sb.String()
+}
+
+// LogfmtEncoder logfmt 编码器：ts=... level=info caller=... msg="..." key=val
+type LogfmtEncoder struct{}
+
+func (e *LogfmtEncoder) EncodeRecord(r *Record) string {
+	var sb strings.Builder
+	appendLogfmtPair(&sb, "ts", formatRecordTime(r))
+	appendLogfmtPair(&sb, "level", r.Level.String())
+	if prefix := r.customPrefix(); prefix != "" {
+		appendLogfmtPair(&sb, "prefix", prefix)
+	}
+	if r.Caller.Defined() {
+		appendLogfmtPair(&sb, "caller", r.Caller.String())
+	}
+	if r.LoggerName != "" {
+		appendLogfmtPair(&sb, "logger", r.LoggerName)
+	}
+	sb.WriteString(" msg=" + strconv.Quote(r.Message))
+	if r.Err != nil && !hasErrorField(r.Fields) {
+		appendLogfmtPair(&sb, "error", r.Err.Error())
+	}
+	appendLogfmtFields(&sb, "", r.Fields)
+	appendLogfmtFields(&sb, "", errorFields(r.Err))
+	if r.Stack != "" {
+		appendLogfmtPair(&sb, "stacktrace", r.Stack)
+	}
+	return sb.String()
+}
+
+func appendLogfmtFields(sb *strings.Builder, prefix string, fields []Field) {
+	for _, f := range fields {
+		if group, ok := f.Value.([]Field); ok {
+			appendLogfmtFields(sb, prefix+f.Key+".", group)
+			continue
+		}
+		appendLogfmtPair(sb, prefix+f.Key, fmt.Sprint(f.Value))
+	}
+}
+
+// appendLogfmtPair 追加 key=value，键中的非法字符替换为下划线，值在需要时加引号并转义
+func appendLogfmtPair(sb *strings.Builder, key, value string) {
+	if sb.Len() > 0 {
+		sb.WriteByte(' ')
+	}
+	sb.WriteString(logfmtKey(key))
+	sb.WriteByte('=')
+	if needsLogfmtQuote(value) {
+		sb.WriteString(strconv.Quote(value))
+	} else {
+		sb.WriteString(value)
+	}
+}
+
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+func needsLogfmtQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
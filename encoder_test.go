@@ -0,0 +1,222 @@
+package logs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRecord 返回时间固定的 INFO 记录，调用方按需修改其他字段
+func testRecord(msg string) *Record {
+	return &Record{
+		Time:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:      LogLevelInfo,
+		Message:    msg,
+		Prefix:     levelTag(LogLevelInfo),
+		TimeLayout: time.RFC3339,
+	}
+}
+
+func TestJsonEncoderRecord(t *testing.T) {
+	const ts = `{"timestamp":"2024-01-02T03:04:05Z",`
+	for _, tc := range []struct {
+		name string
+		edit func(r *Record)
+		want string
+	}{
+		{
+			"escaping",
+			func(r *Record) { r.Message = "say \"hi\"\n<b>&\x00" },
+			ts + `"level":"info","message":"say \"hi\"\n\u003cb\u003e\u0026\u0000"}`,
+		},
+		{
+			"key order",
+			func(r *Record) {
+				r.Prefix = "[INFO] svc "
+				r.Caller = Caller{File: "/abs/x.go", Line: 3}
+				r.LoggerName = "db"
+				r.Fields = []Field{F("n", 1), Group("g", F("k", "v")), F(`k"ey`, []int{1})}
+				r.Stack = "main.f\n\t/a.go:1"
+			},
+			ts + `"level":"info","prefix":"svc","file":"/abs/x.go:3","logger":"db","message":"m",` +
+				`"n":1,"g":{"k":"v"},"k\"ey":[1],"stacktrace":"main.f\n\t/a.go:1"}`,
+		},
+		{
+			"error",
+			func(r *Record) {
+				r.Level, r.Prefix = LogLevelError, levelTag(LogLevelError)
+				r.Err = fmt.Errorf("wrap: %w", io.EOF)
+			},
+			ts + `"level":"error","message":"m","error":"wrap: EOF","error_type":"*fmt.wrapError",` +
+				`"error_causes":[{"type":"*errors.errorString","message":"EOF"}]}`,
+		},
+		{
+			"error field",
+			func(r *Record) {
+				r.Err = io.EOF
+				r.Fields = []Field{F("err", io.EOF)}
+			},
+			ts + `"level":"info","message":"m","err":"EOF","error_type":"*errors.errorString"}`,
+		},
+		{
+			"epoch",
+			func(r *Record) { r.TimeLayout = TimeFormatEpochMillis },
+			`{"timestamp":1704164645000,"level":"info","message":"m"}`,
+		},
+	} {
+		r := testRecord("m")
+		tc.edit(r)
+		if got := (&JsonEncoder{}).EncodeRecord(r); got != tc.want {
+			t.Errorf("%s:\n got %s\nwant %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPlainEncoderRecord(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		edit func(r *Record)
+		want string
+	}{
+		{
+			"flags",
+			func(r *Record) {
+				r.TimeLayout = ""
+				r.Flags = Ldate | Ltime
+				r.Fields = []Field{F("a", "x y"), F("b", ""), F("c", "k=v")}
+			},
+			`[INFO] 2024/01/02 03:04:05 m a="x y" b="" c="k=v"`,
+		},
+		{
+			"msgprefix",
+			func(r *Record) {
+				r.TimeLayout = ""
+				r.Flags = Ltime | Lmsgprefix
+			},
+			`03:04:05 [INFO] m`,
+		},
+		{
+			"shortfile",
+			func(r *Record) {
+				r.Flags = Lshortfile
+				r.Caller = Caller{File: "/abs/x.go", Line: 3}
+			},
+			`[INFO] 2024-01-02T03:04:05Z x.go:3: m`,
+		},
+		{
+			"rootfile and name",
+			func(r *Record) {
+				r.Flags = Lrootfile
+				r.Caller = Caller{File: "/abs/x.go", Line: 3}
+				r.LoggerName = "db"
+			},
+			`[INFO] 2024-01-02T03:04:05Z /abs/x.go 3: [db] m`,
+		},
+		{
+			"error and group",
+			func(r *Record) {
+				r.Message = "wrap: EOF"
+				r.Err = fmt.Errorf("wrap: %w", io.EOF)
+				r.Fields = []Field{Group("req", F("id", 7))}
+			},
+			`[INFO] 2024-01-02T03:04:05Z wrap: EOF req.id=7 error_type=*fmt.wrapError error_causes="EOF (*errors.errorString)"`,
+		},
+		{
+			"stack",
+			func(r *Record) { r.Stack = "main.f\n\t/a.go:1" },
+			"[INFO] 2024-01-02T03:04:05Z m\n\tmain.f\n\t\t/a.go:1",
+		},
+	} {
+		r := testRecord("m")
+		tc.edit(r)
+		if got := (&PlainEncoder{}).EncodeRecord(r); got != tc.want {
+			t.Errorf("%s:\n got %q\nwant %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+type upperEncoder struct{}
+
+func (upperEncoder) Encode(v ...interface{}) string {
+	return fmt.Sprint(v...) + "!"
+}
+
+// 旧的 Encoder 只负责消息，前缀、时间和字段仍按 plain 格式输出
+func TestAdaptEncoder(t *testing.T) {
+	r := testRecord("m")
+	r.Args = []interface{}{"a", 1}
+	r.Fields = []Field{F("k", "v")}
+	if got, want := AdaptEncoder(upperEncoder{}).EncodeRecord(r), "[INFO] 2024-01-02T03:04:05Z a1! k=v"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if _, ok := AdaptEncoder(&JsonEncoder{}).(*JsonEncoder); !ok {
+		t.Fatal("RecordEncoder wrapped again")
+	}
+}
+
+func TestLogfmtEncoderRecord(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		edit func(r *Record)
+		want string
+	}{
+		{
+			"quoting",
+			func(r *Record) {
+				r.Message = `say "hi"`
+				r.Fields = []Field{F("plain", "v"), F("space", "a b"), F("eq", "k=v"), F("empty", ""), F("nl", "a\nb"), F("bs", `a\b`)}
+			},
+			`ts=2024-01-02T03:04:05Z level=info msg="say \"hi\"" plain=v space="a b" eq="k=v" empty="" nl="a\nb" bs="a\\b"`,
+		},
+		{
+			"keys",
+			func(r *Record) {
+				r.Fields = []Field{F("a key", 1), F(`q"=`, 2), F("", 3), Group("req", F("id", 7), Group("u", F("n", "x")))}
+			},
+			`ts=2024-01-02T03:04:05Z level=info msg="m" a_key=1 q__=2 _=3 req.id=7 req.u.n=x`,
+		},
+		{
+			"header order",
+			func(r *Record) {
+				r.Prefix = "[INFO] svc "
+				r.Caller = Caller{File: "/abs/x.go", Line: 3}
+				r.LoggerName = "db"
+			},
+			`ts=2024-01-02T03:04:05Z level=info prefix=svc caller=/abs/x.go:3 logger=db msg="m"`,
+		},
+		{
+			"error and stack",
+			func(r *Record) {
+				r.Err = io.EOF
+				r.Stack = "main.f\n\t/a.go:1"
+			},
+			`ts=2024-01-02T03:04:05Z level=info msg="m" error=EOF error_type=*errors.errorString stacktrace="main.f\n\t/a.go:1"`,
+		},
+		{
+			"epoch",
+			func(r *Record) { r.TimeLayout = TimeFormatEpoch },
+			`ts=1704164645 level=info msg="m"`,
+		},
+	} {
+		r := testRecord("m")
+		tc.edit(r)
+		if got := (&LogfmtEncoder{}).EncodeRecord(r); got != tc.want {
+			t.Errorf("%s:\n got %s\nwant %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSetEncodingLogfmt(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	if err := l.SetEncoding(LogEncodingLogfmt); err != nil {
+		t.Fatal(err)
+	}
+	l.Infow("started", "port", 8080)
+	got := buf.String()
+	if !strings.HasPrefix(got, "ts=") || !strings.Contains(got, ` level=info caller=encoder_test.go:`) ||
+		!strings.HasSuffix(got, ` msg="started" port=8080`+"\n") {
+		t.Fatalf("got %q", got)
+	}
+}
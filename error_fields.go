@@ -0,0 +1,126 @@
+package logs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrorFielder 错误可以实现该接口，提供附加到日志中的结构化字段（输出在 error_fields 中）
+type ErrorFielder interface {
+	LogFields() map[string]interface{}
+}
+
+// ErrorCause 错误链中的一个原因
+type ErrorCause struct {
+	Type    string `json:"type"`    // 具体类型，如 "*fs.PathError"
+	Message string `json:"message"` // err.Error()
+}
+
+func (c ErrorCause) String() string {
+	return c.Message + " (" + c.Type + ")"
+}
+
+// ErrorCauses 错误链展开后的原因列表，文本格式下以 "; " 分隔
+type ErrorCauses []ErrorCause
+
+func (cs ErrorCauses) String() string {
+	parts := make([]string, len(cs))
+	for i, c := range cs {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// maxErrorCauses 最多展开的原因数量，防止错误链过长或成环
+const maxErrorCauses = 32
+
+// errorFields 返回描述错误的附加字段：error_type、error_causes（通过 errors.Unwrap 和 errors.Join 展开）
+// 以及 error_fields（错误链中实现了 ErrorFielder 的错误提供的字段）；不包含 error 本身
+func errorFields(err error) []Field {
+	if err == nil {
+		return nil
+	}
+
+	fields := []Field{F("error_type", fmt.Sprintf("%T", err))}
+	if causes := appendErrorCauses(nil, err); len(causes) > 0 {
+		fields = append(fields, F("error_causes", causes))
+	}
+	if extra := errorLogFields(err); len(extra) > 0 {
+		fields = append(fields, Group("error_fields", extra...))
+	}
+	return fields
+}
+
+// appendErrorCauses 深度优先展开 err 的原因，不包含 err 本身
+func appendErrorCauses(causes ErrorCauses, err error) ErrorCauses {
+	var next []error
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		next = u.Unwrap()
+	case interface{ Unwrap() error }:
+		next = []error{u.Unwrap()}
+	}
+
+	for _, cause := range next {
+		if cause == nil || len(causes) >= maxErrorCauses {
+			continue
+		}
+		causes = append(causes, ErrorCause{Type: fmt.Sprintf("%T", cause), Message: cause.Error()})
+		causes = appendErrorCauses(causes, cause)
+	}
+	return causes
+}
+
+// errorLogFields 收集错误链中所有 ErrorFielder 提供的字段，同名字段以外层错误为准，按键名排序
+func errorLogFields(err error) []Field {
+	values := make(map[string]interface{})
+	collectErrorLogFields(values, err, 0)
+	if len(values) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := make([]Field, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, F(k, values[k]))
+	}
+	return fields
+}
+
+func collectErrorLogFields(values map[string]interface{}, err error, depth int) {
+	if err == nil || depth > maxErrorCauses {
+		return
+	}
+	if fe, ok := err.(ErrorFielder); ok {
+		for k, v := range fe.LogFields() {
+			if _, exists := values[k]; !exists {
+				values[k] = v
+			}
+		}
+	}
+
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, cause := range u.Unwrap() {
+			collectErrorLogFields(values, cause, depth+1)
+		}
+	case interface{ Unwrap() error }:
+		collectErrorLogFields(values, u.Unwrap(), depth+1)
+	}
+}
+
+// firstErrorField 返回第一个值为 error 的字段中的错误
+func firstErrorField(fields []Field) error {
+	for _, f := range fields {
+		if err, ok := f.Value.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package logs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fieldsError 带结构化字段的错误
+type fieldsError struct {
+	msg    string
+	fields map[string]interface{}
+	cause  error
+}
+
+func (e *fieldsError) Error() string                     { return e.msg }
+func (e *fieldsError) Unwrap() error                     { return e.cause }
+func (e *fieldsError) LogFields() map[string]interface{} { return e.fields }
+
+// loopError 的 Unwrap 返回自身，展开时需要在上限处停止
+type loopError struct{}
+
+func (e *loopError) Error() string { return "loop" }
+func (e *loopError) Unwrap() error { return e }
+
+func TestErrorFields(t *testing.T) {
+	eof := ErrorCause{Type: "*errors.errorString", Message: "EOF"}
+	inner := &fieldsError{msg: "inner", fields: map[string]interface{}{"id": 1, "table": "users"}}
+	outer := &fieldsError{msg: "outer", fields: map[string]interface{}{"id": 2}, cause: inner}
+
+	for _, tc := range []struct {
+		name string
+		err  error
+		want []Field
+	}{
+		{"nil", nil, nil},
+		{"plain", io.EOF, []Field{F("error_type", "*errors.errorString")}},
+		{
+			"wrapped",
+			fmt.Errorf("read: %w", io.EOF),
+			[]Field{F("error_type", "*fmt.wrapError"), F("error_causes", ErrorCauses{eof})},
+		},
+		{
+			"joined", // 深度优先展开
+			errors.Join(errors.New("a"), fmt.Errorf("b: %w", io.EOF)),
+			[]Field{F("error_type", "*errors.joinError"), F("error_causes", ErrorCauses{
+				{Type: "*errors.errorString", Message: "a"},
+				{Type: "*fmt.wrapError", Message: "b: EOF"},
+				eof,
+			})},
+		},
+		{
+			"log fields", // 同名字段以外层为准，按键名排序
+			fmt.Errorf("query: %w", outer),
+			[]Field{
+				F("error_type", "*fmt.wrapError"),
+				F("error_causes", ErrorCauses{
+					{Type: "*logs.fieldsError", Message: "outer"},
+					{Type: "*logs.fieldsError", Message: "inner"},
+				}),
+				Group("error_fields", F("id", 2), F("table", "users")),
+			},
+		},
+	} {
+		if got := errorFields(tc.err); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s:\n got %v\nwant %v", tc.name, got, tc.want)
+		}
+	}
+
+	fields := errorFields(&loopError{})
+	if causes := fields[1].Value.(ErrorCauses); len(causes) != maxErrorCauses {
+		t.Fatalf("loop: %d causes, want %d", len(causes), maxErrorCauses)
+	}
+}
+
+// 参数或字段中的错误作为 error 输出，并展开原因链
+func TestLogError(t *testing.T) {
+	err := fmt.Errorf("save: %w", &fieldsError{msg: "denied", fields: map[string]interface{}{"user": "tom"}})
+	for _, tc := range []struct {
+		log  func(l *LogsLogger)
+		want string
+	}{
+		{
+			func(l *LogsLogger) { l.Error(err) },
+			`"message":"save: denied","error":"save: denied","error_type":"*fmt.wrapError",` +
+				`"error_causes":[{"type":"*logs.fieldsError","message":"denied"}],"error_fields":{"user":"tom"}}`,
+		},
+		{
+			func(l *LogsLogger) { l.Errorw("failed", "err", err) },
+			`"message":"failed","err":"save: denied","error_type":"*fmt.wrapError",` +
+				`"error_causes":[{"type":"*logs.fieldsError","message":"denied"}],"error_fields":{"user":"tom"}}`,
+		},
+		{
+			func(l *LogsLogger) { l.Info("no error") },
+			`"message":"no error"}`,
+		},
+	} {
+		l, buf := newBufferLogger(t)
+		if err := l.SetEncoding(LogEncodingJSON); err != nil {
+			t.Fatal(err)
+		}
+		tc.log(l)
+		got := strings.TrimSpace(buf.String())
+		if !strings.HasSuffix(got, tc.want) || !json.Valid([]byte(got)) {
+			t.Errorf("got  %s\nwant suffix %s", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package logs
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Field 结构化日志字段（键值对）
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F 创建一个结构化字段
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Group 创建一个分组字段，JSON 模式下输出为嵌套对象，plain 模式下输出为 group.key=value
+func Group(key string, fields ...Field) Field {
+	return Field{Key: key, Value: fields}
+}
+
+// With 返回附带了结构化字段的子日志器，参数可以是 Field，也可以是交替出现的键和值。
+// 子日志器使用父日志器当前的输出配置，父日志器修改配置后随之变化；子日志器自己修改配置后不再跟随
+//
+//	logger.With("user_id", 42, logs.F("order_id", "A001")).Info("下单成功")
+func (l *LogsLogger) With(keysAndValues ...interface{}) *LogsLogger {
+	return l.withFields(toFields(keysAndValues))
+}
+
+// WithFields 返回附带了 fields 中所有字段的子日志器，字段按键名排序
+func (l *LogsLogger) WithFields(fields map[string]interface{}) *LogsLogger {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fs := make([]Field, 0, len(keys))
+	for _, k := range keys {
+		fs = append(fs, Field{Key: k, Value: fields[k]})
+	}
+	return l.withFields(fs)
+}
+
+func (l *LogsLogger) withFields(fields []Field) *LogsLogger {
+	child := l.clone()
+	child.fields = make([]Field, 0, len(l.fields)+len(fields))
+	child.fields = append(child.fields, l.fields...)
+	child.fields = append(child.fields, fields...)
+	return child
+}
+
+// clone 复制日志器，子日志器与父日志器共享级别、输出配置快照和输出目标。
+// 子日志器借用父日志器的日志文件，不持有引用。只读取不变的字段和原子字段，不加锁，每个请求调用也不会与重新配置互相等待
+func (l *LogsLogger) clone() *LogsLogger {
+	c := l.out.Load()
+	if c == nil { // 没有发布过配置的日志器
+		mu2.Lock()
+		if c = l.out.Load(); c == nil {
+			l.publish()
+			c = l.out.Load()
+		}
+		mu2.Unlock()
+	}
+	child := &LogsLogger{
+		fields:  l.fields,
+		handler: l.handler,
+		named:   l.named,
+	}
+	child.level.Store(l.level.Load())
+	if c.owner != l {
+		child.sinks.Store(l.sinks.Load()) // 修改过输出目标的子日志器，其子日志器使用修改后的输出目标
+	}
+	child.out.Store(c)
+	return child
+}
+
+// toFields 将 Field 或交替出现的键值参数转换为字段列表
+func toFields(keysAndValues []interface{}) []Field {
+	fields := make([]Field, 0, len(keysAndValues)/2+1)
+	for i := 0; i < len(keysAndValues); i++ {
+		switch kv := keysAndValues[i].(type) {
+		case Field:
+			fields = append(fields, kv)
+		case []Field:
+			fields = append(fields, kv...)
+		default:
+			if i == len(keysAndValues)-1 {
+				// 落单的值，没有对应的键
+				fields = append(fields, Field{Key: "!BADKEY", Value: kv})
+				break
+			}
+			key, ok := kv.(string)
+			if !ok {
+				key = fmt.Sprint(kv)
+			}
+			fields = append(fields, Field{Key: key, Value: keysAndValues[i+1]})
+			i++
+		}
+	}
+	return fields
+}
+
+// mergeFields 合并日志器自带的字段和单次调用传入的字段
+func mergeFields(base []Field, extra []Field) []Field {
+	if len(extra) == 0 {
+		return base
+	}
+	if len(base) == 0 {
+		return extra
+	}
+	fields := make([]Field, 0, len(base)+len(extra))
+	fields = append(fields, base...)
+	return append(fields, extra...)
+}
+
+// appendPlainFields 以 key=value 的形式追加字段
+func appendPlainFields(sb *strings.Builder, fields []Field) {
+	appendPlainGroup(sb, "", fields)
+}
+
+func appendPlainGroup(sb *strings.Builder, prefix string, fields []Field) {
+	for _, f := range fields {
+		if group, ok := f.Value.([]Field); ok {
+			appendPlainGroup(sb, prefix+f.Key+".", group)
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(prefix + f.Key)
+		sb.WriteByte('=')
+		sb.WriteString(plainValue(f.Value))
+	}
+}
+
+// plainValue 将字段值转换为文本，包含空白、引号或等号时加引号
+func plainValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
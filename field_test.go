@@ -0,0 +1,82 @@
+package logs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToFields(t *testing.T) {
+	for _, tc := range []struct {
+		args []interface{}
+		want []Field
+	}{
+		{nil, []Field{}},
+		{[]interface{}{"a", 1, "b", "x"}, []Field{F("a", 1), F("b", "x")}},
+		{[]interface{}{F("a", 1), "b", 2}, []Field{F("a", 1), F("b", 2)}},
+		{[]interface{}{[]Field{F("a", 1), F("b", 2)}}, []Field{F("a", 1), F("b", 2)}},
+		{[]interface{}{7, "seven"}, []Field{F("7", "seven")}},                         // 非字符串的键
+		{[]interface{}{"a", 1, "lonely"}, []Field{F("a", 1), F("!BADKEY", "lonely")}}, // 落单的值
+	} {
+		if got := toFields(tc.args); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("toFields(%v) = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+}
+
+// With、WithFields 和 *w 方法的字段在 plain 和 JSON 模式下按添加顺序输出，子日志器的字段不影响父日志器
+func TestStructuredFields(t *testing.T) {
+	for _, tc := range []struct {
+		log   func(l *LogsLogger)
+		plain string
+		json  string
+	}{
+		{
+			func(l *LogsLogger) { l.With("user_id", 42).Infow("done", "order", "A 1") },
+			`done user_id=42 order="A 1"`,
+			`"message":"done","user_id":42,"order":"A 1"`,
+		},
+		{
+			func(l *LogsLogger) { l.WithFields(map[string]interface{}{"b": 2, "a": true}).Info("sorted") },
+			`sorted a=true b=2`,
+			`"message":"sorted","a":true,"b":2`,
+		},
+		{
+			func(l *LogsLogger) { l.With("a", 1).With(F("b", "x=y")).Warnw("nested", "c", nil) },
+			`nested a=1 b="x=y" c=<nil>`,
+			`"message":"nested","a":1,"b":"x=y","c":null`,
+		},
+		{
+			func(l *LogsLogger) { l.Errorw("group", Group("req", F("id", 7), F("path", "/a"))) },
+			`group req.id=7 req.path=/a`,
+			`"message":"group","req":{"id":7,"path":"/a"}`,
+		},
+		{
+			func(l *LogsLogger) {
+				l.With("child", true)
+				l.Infof("parent %d", 1)
+			},
+			`parent 1`,
+			`"message":"parent 1"}`,
+		},
+	} {
+		for _, encoding := range []string{LogEncodingPlain, LogEncodingJSON} {
+			l, buf := newBufferLogger(t)
+			if err := l.SetEncoding(encoding); err != nil {
+				t.Fatal(err)
+			}
+			tc.log(l)
+
+			got := strings.TrimSpace(buf.String())
+			if encoding == LogEncodingJSON {
+				if !strings.Contains(got, tc.json) {
+					t.Errorf("json: got %q, want %q", got, tc.json)
+				}
+				continue
+			}
+			if _, msg, _ := strings.Cut(got, ": "); msg != tc.plain { // 去掉前缀、时间和调用者
+				t.Errorf("plain: got %q, want %q", got, tc.plain)
+			}
+		}
+	}
+}
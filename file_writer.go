@@ -0,0 +1,178 @@
+package logs
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// sharedFileWriter 一个日志文件对应一个写入器（lumberjack 或 timeRotator），指向同一路径的日志器共享它，避免争抢切割
+type sharedFileWriter struct {
+	path string // 绝对路径
+	out  io.WriteCloser
+	rot  rotationConf
+
+	handles map[*fileHandle]bool           // 持有该写入器的引用，由 fileWritersMu 保护
+	hooks   atomic.Pointer[[]*rotateHooks] // 持有者的切割钩子，切割时（持有 writeMu）无锁读取
+	closed  bool                           // 最后一个引用释放后关闭，由 writeMu 保护
+}
+
+// rotationConf 日志文件的切割参数
+type rotationConf struct {
+	rotation    string
+	filePattern string
+	maxSize     int
+	maxBackups  int
+	keepDays    int
+	compress    bool
+	location    *time.Location
+}
+
+// rotationOf 从配置中取出切割参数，时区与日志时间一致
+func rotationOf(conf LogConf) rotationConf {
+	loc, _ := loadTimeZone(conf.TimeZone)
+	return rotationConf{
+		rotation:    conf.Rotation,
+		filePattern: conf.FilePattern,
+		maxSize:     conf.MaxSize,
+		maxBackups:  conf.MaxBackups,
+		keepDays:    conf.KeepDays,
+		compress:    conf.Compress,
+		location:    loc,
+	}
+}
+
+// rotateFunc 写入器切割时调用：closedPath 为切割出的文件，wait 等待清理、压缩完成后返回最终的文件，
+// 文件已被删除时返回 false
+type rotateFunc func(closedPath string, wait func() (string, bool))
+
+// newFileOutput 按切割策略创建写入器：按大小切割时使用 lumberjack，按时间切割时使用 timeRotator
+func newFileOutput(path string, rot rotationConf, onRotate rotateFunc) io.WriteCloser {
+	if period, _, _ := parseRotation(rot.rotation); period != "" {
+		r := newTimeRotator(path, rot)
+		r.onRotate = onRotate
+		return r
+	}
+	return newLumberjackWriter(&lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    rot.maxSize,
+		MaxBackups: rot.maxBackups,
+		MaxAge:     rot.keepDays,
+		Compress:   rot.compress,
+	}, onRotate)
+}
+
+// fileHandle 日志器持有的文件写入器引用，由打开它的日志器在 Close 或改用其他输出时释放。
+// With、Named 创建的子日志器借用父日志器的引用，不单独计数
+type fileHandle struct {
+	w        *sharedFileWriter
+	hooks    *rotateHooks // 持有者注册的切割钩子，可以为空
+	released atomic.Bool
+}
+
+var (
+	fileWritersMu sync.Mutex
+	fileWriters   = make(map[string]*sharedFileWriter) // 绝对路径 -> 写入器
+)
+
+// acquireFileWriter 获取 path 对应的文件写入器，不存在时创建；切割参数以最后一次获取时的配置为准。
+// hooks 为持有者的切割钩子，文件切割后调用
+func acquireFileWriter(path string, conf LogConf, hooks *rotateHooks) *fileHandle {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = filepath.Clean(path)
+	}
+
+	fileWritersMu.Lock()
+	defer fileWritersMu.Unlock()
+
+	rot := rotationOf(conf)
+
+	w, ok := fileWriters[absPath]
+	if !ok {
+		w = &sharedFileWriter{path: absPath, rot: rot, handles: make(map[*fileHandle]bool)}
+		w.out = newFileOutput(absPath, rot, w.rotated)
+		fileWriters[absPath] = w
+	} else if w.rot != rot {
+		// 后台清理协程会无锁读取这些参数，因此不修改原实例，而是关闭后替换
+		writeMu.Lock()
+		w.out.Close()
+		w.out = newFileOutput(absPath, rot, w.rotated)
+		w.rot = rot
+		writeMu.Unlock()
+	}
+
+	h := &fileHandle{w: w, hooks: hooks}
+	w.handles[h] = true
+	w.updateHooks()
+	return h
+}
+
+// updateHooks 重新收集持有者的切割钩子，调用方持有 fileWritersMu
+func (w *sharedFileWriter) updateHooks() {
+	var sets []*rotateHooks
+	for h := range w.handles {
+		if h.hooks != nil && !containsHooks(sets, h.hooks) {
+			sets = append(sets, h.hooks)
+		}
+	}
+	w.hooks.Store(&sets)
+}
+
+// Write 写入日志文件，调用方（writeLine）持有 writeMu。
+// 文件的所有引用都释放后的写入（如父日志器关闭后子日志器的写入）被丢弃，文件不会在登记之外重新打开
+func (h *fileHandle) Write(p []byte) (int, error) {
+	if h.w.closed {
+		return len(p), nil
+	}
+	return h.w.out.Write(p)
+}
+
+// release 释放引用，多次调用只释放一次；文件的最后一个引用释放时关闭文件
+func (h *fileHandle) release() {
+	if h == nil || !h.released.CompareAndSwap(false, true) {
+		return
+	}
+
+	fileWritersMu.Lock()
+	defer fileWritersMu.Unlock()
+	delete(h.w.handles, h)
+	h.w.updateHooks()
+	if len(h.w.handles) > 0 {
+		return
+	}
+	if fileWriters[h.w.path] == h.w {
+		delete(fileWriters, h.w.path)
+	}
+	writeMu.Lock()
+	h.w.closed = true
+	h.w.out.Close()
+	writeMu.Unlock()
+}
+
+// closeFileWriters 关闭所有日志文件的句柄，之后的写入会重新打开文件
+func closeFileWriters() error {
+	fileWritersMu.Lock()
+	defer fileWritersMu.Unlock()
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	var errs []error
+	for _, w := range fileWriters {
+		if err := w.out.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ReopenFiles 关闭所有日志文件，下一次写入时按原路径重新打开。
+// 用于配合 logrotate 等外部工具：文件被移走后调用，之后的日志写入新文件
+func ReopenFiles() error {
+	return closeFileWriters()
+}
@@ -0,0 +1,138 @@
+package logs
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSharedFileWriterAcrossLoggers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	a, err := NewLogger(LogConf{Mode: LogModeFile, Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewLogger(LogConf{Mode: LogModeFile, Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := fileRefs(t, path); n != 2 {
+		t.Fatalf("refs = %d, want 2", n)
+	}
+
+	if err := a.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Close(context.Background()); err != nil { // 多次关闭只释放一次
+		t.Fatal(err)
+	}
+	if n := fileRefs(t, path); n != 1 {
+		t.Fatalf("refs after closing a = %d, want 1", n)
+	}
+	b.Info("from b")
+	if err := b.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := fileRefs(t, path); n != 0 {
+		t.Fatalf("refs after closing b = %d, want 0", n)
+	}
+	if got := readFile(t, path); !strings.Contains(got, "from b") {
+		t.Fatalf("file = %q", got)
+	}
+}
+
+// 子日志器不需要关闭：父日志器改用其他文件时旧文件立即释放，子日志器随父日志器写入新文件
+func TestChildFollowsParentSetUp(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.log")
+	newPath := filepath.Join(dir, "new.log")
+	parent, err := NewLogger(LogConf{Mode: LogModeFile, Path: oldPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := parent.With("child", true)
+	grandchild := child.Named("sub")
+	child.Info("to old file")
+
+	if err := parent.SetUp(LogConf{Mode: LogModeFile, Path: newPath}); err != nil {
+		t.Fatal(err)
+	}
+	if n := fileRefs(t, oldPath); n != 0 {
+		t.Fatalf("old file refs = %d, want 0", n)
+	}
+	child.Info("to new file")
+	grandchild.Info("from grandchild")
+	if got := readFile(t, newPath); !strings.Contains(got, "to new file") || !strings.Contains(got, "from grandchild") {
+		t.Fatalf("new file = %q", got)
+	}
+	if got := readFile(t, oldPath); strings.Contains(got, "to new file") {
+		t.Fatalf("old file = %q", got)
+	}
+
+	if err := parent.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := fileRefs(t, newPath); n != 0 {
+		t.Fatalf("new file refs after parent close = %d, want 0", n)
+	}
+}
+
+// 每个请求创建一个子日志器且从不关闭：子日志器不计入引用，父日志器关闭时文件被关闭，
+// 之后子日志器的写入被丢弃，不会在登记之外重新打开文件
+func TestConcurrentChildRefs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	parent, err := NewLogger(LogConf{Mode: LogModeFile, Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	children := make(chan *LogsLogger, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			child := parent.With("i", i)
+			child.Info("request")
+			children <- child
+		}(i)
+	}
+	wg.Wait()
+	close(children)
+	if n := fileRefs(t, path); n != 1 {
+		t.Fatalf("refs = %d, want 1", n)
+	}
+
+	if err := parent.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := fileRefs(t, path); n != 0 {
+		t.Fatalf("refs after parent close = %d, want 0", n)
+	}
+	for child := range children {
+		child.Info("after close")
+	}
+	if n := fileRefs(t, path); n != 0 {
+		t.Fatalf("refs after child writes = %d, want 0", n)
+	}
+	if got := readFile(t, path); strings.Count(got, "request") != 100 || strings.Contains(got, "after close") {
+		t.Fatalf("file = %q", got)
+	}
+}
+
+// 释放后的引用仍能写入其他日志器打开着的文件（如重新配置前入队的日志），文件关闭后的写入被丢弃
+func TestReleasedHandleWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	old := acquireFileWriter(path, LogConf{}, nil)
+	cur := acquireFileWriter(path, LogConf{}, nil)
+	old.release()
+
+	writeTo(old, "queued before reconfigure")
+	cur.release()
+	writeTo(old, "after close")
+	if got := readFile(t, path); !strings.Contains(got, "queued before reconfigure") || strings.Contains(got, "after close") {
+		t.Fatalf("file = %q", got)
+	}
+}
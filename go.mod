@@ -2,4 +2,8 @@ module github.com/chenzanhong/logs
 
 go 1.23.0
 
-require gopkg.in/natefinch/lumberjack.v2 v2.2.1
+require (
+	github.com/BurntSushi/toml v1.5.0
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,255 @@
+package logs
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRequestIDHeader 默认读取和返回请求 ID 的请求头
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen 请求中携带的请求 ID 的最大长度，超过时重新生成
+const maxRequestIDLen = 128
+
+// HTTPOptions HTTPMiddleware 的选项
+type HTTPOptions struct {
+	SkipPaths       []string                   // 不记录访问日志的路径（精确匹配），如 "/healthz"；请求仍会注入日志器（不带 request_id）
+	Skip            func(r *http.Request) bool // 在处理请求之前调用，返回 true 时与 SkipPaths 相同
+	RequestIDHeader string                     // 请求 ID 的请求头，为空时使用 X-Request-ID；请求中没有或不合法时自动生成
+	Message         string                     // 访问日志的消息，为空时为 "http request"
+	CombinedFormat  bool                       // plain 编码时按 Apache combined 格式输出访问日志，其他编码不受影响
+}
+
+// HTTPMiddleware 返回记录访问日志的 net/http 中间件：
+//
+//	mux := http.NewServeMux()
+//	http.ListenAndServe(":8080", logs.HTTPMiddleware(logger, logs.HTTPOptions{SkipPaths: []string{"/healthz"}})(mux))
+//
+// 每个请求会注入带 request_id 字段的日志器，处理函数中通过 logs.FromContext(r.Context()) 获取。
+// 请求中的请求 ID 超过 128 个字符或含有字母、数字和 -_.:/+= 以外的字符时重新生成。
+// 访问日志包含 method、path、status、size、latency、remote_addr、user_agent，
+// 状态码为 5xx 时以 ERROR 级别输出，4xx 时为 WARN，其余为 INFO；处理函数 panic 时也会输出（状态码未写入时记为 500）。
+// logger 为空时使用全局日志器
+func HTTPMiddleware(logger *LogsLogger, opts HTTPOptions) func(http.Handler) http.Handler {
+	if logger == nil {
+		logger = globalLogger
+	}
+	header := opts.RequestIDHeader
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	msg := opts.Message
+	if msg == "" {
+		msg = "http request"
+	}
+	skip := make(map[string]bool, len(opts.SkipPaths))
+	for _, p := range opts.SkipPaths {
+		skip[p] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			id := r.Header.Get(header)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(header, id)
+
+			// 不记录访问日志的请求（如健康检查）不创建子日志器
+			if skip[r.URL.Path] || (opts.Skip != nil && opts.Skip(r)) {
+				next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), logger)))
+				return
+			}
+
+			// With 不加锁，也不持有文件引用，请求结束后不需要关闭
+			reqLogger := logger.With("request_id", id)
+			r = r.WithContext(NewContext(r.Context(), reqLogger))
+
+			rw := &responseRecorder{ResponseWriter: w}
+			completed := false
+			defer func() {
+				status := rw.statusCode()
+				if !completed && rw.status == 0 {
+					status = http.StatusInternalServerError // panic 时 net/http 不会写入响应
+				}
+				logAccess(reqLogger, msg, opts.CombinedFormat, r, status, rw.size, start)
+			}()
+			next.ServeHTTP(rw, r)
+			completed = true
+		})
+	}
+}
+
+// httpStatusLevel 按状态码选择访问日志的级别
+func httpStatusLevel(status int) LogLevel {
+	switch {
+	case status >= 500:
+		return LogLevelError
+	case status >= 400:
+		return LogLevelWarn
+	default:
+		return LogLevelInfo
+	}
+}
+
+// logAccess 输出一条访问日志；访问日志的调用位置没有意义，因此不记录调用者
+func logAccess(logger *LogsLogger, msg string, combined bool, r *http.Request, status int, size int64, start time.Time) {
+	level := httpStatusLevel(status)
+	if !logger.enabled(level) {
+		return
+	}
+
+	rec := newRecord(logger, level, []Field{
+		F("method", r.Method),
+		F("path", r.URL.Path),
+		F("status", status),
+		F("size", size),
+		F("latency", time.Since(start)),
+		F("remote_addr", remoteHost(r)),
+		F("user_agent", r.UserAgent()),
+	})
+	rec.Message = msg
+	rec.Args = []interface{}{msg}
+
+	// 使用 plain 编码的输出写入 Apache combined 格式，其他编码的输出不受影响
+	var plainLine string
+	if combined {
+		plainLine = combinedLogLine(r, status, size, start)
+	}
+	emitRecordPlain(logger, rec, plainLine)
+}
+
+// combinedLogLine 按 Apache combined 格式生成访问日志：
+// host - user [time] "method uri proto" status size "referer" "user-agent"
+func combinedLogLine(r *http.Request, status int, size int64, start time.Time) string {
+	user := "-"
+	if r.URL.User != nil {
+		if name := r.URL.User.Username(); name != "" {
+			user = name
+		}
+	}
+	if name, _, ok := r.BasicAuth(); ok && name != "" {
+		user = name
+	}
+	bytes := "-"
+	if size > 0 {
+		bytes = strconv.FormatInt(size, 10)
+	}
+
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s %s %s",
+		remoteHost(r), user, start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method, r.RequestURI, r.Proto, status, bytes,
+		strconv.Quote(orDash(r.Referer())), strconv.Quote(orDash(r.UserAgent())))
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// remoteHost 返回客户端地址中的主机部分
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// validRequestID 请求中携带的请求 ID 是否可以直接使用：不为空、不超过 maxRequestIDLen，
+// 只含有字母、数字和 -_.:/+=，避免把任意内容写入日志和响应头
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' {
+			continue
+		}
+		switch c {
+		case '-', '_', '.', ':', '/', '+', '=':
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// newRequestID 生成 16 位十六进制的随机请求 ID
+func newRequestID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// responseRecorder 记录响应的状态码和大小
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (rw *responseRecorder) WriteHeader(code int) {
+	if rw.status == 0 {
+		rw.status = code
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *responseRecorder) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.Write(b)
+	rw.size += int64(n)
+	return n, err
+}
+
+// statusCode 处理函数没有写入任何内容时，net/http 会返回 200
+func (rw *responseRecorder) statusCode() int {
+	if rw.status == 0 {
+		return http.StatusOK
+	}
+	return rw.status
+}
+
+// Flush 支持流式响应
+func (rw *responseRecorder) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack 支持 WebSocket 等需要接管连接的处理函数
+func (rw *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("http.Hijacker is not supported")
+	}
+	if rw.status == 0 {
+		rw.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
+
+// Unwrap 供 http.ResponseController 访问原始的 ResponseWriter
+func (rw *responseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
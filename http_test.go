@@ -0,0 +1,105 @@
+package logs
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newBufferLogger 返回只写入 buf 的同步日志器
+func newBufferLogger(t *testing.T) (*LogsLogger, *bytes.Buffer) {
+	t.Helper()
+	l, err := NewLogger(LogConf{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	s, err := NewSink("buf", buf, LogLevelDebug, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddSink(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.RemoveSink(LogModeConsole); err != nil {
+		t.Fatal(err)
+	}
+	return l, buf
+}
+
+func TestHTTPMiddlewareRequestID(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	h := HTTPMiddleware(l, HTTPOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, tc := range []struct {
+		id   string
+		keep bool
+	}{
+		{"req-123:a/b+c=", true},
+		{strings.Repeat("a", maxRequestIDLen), true},
+		{strings.Repeat("a", maxRequestIDLen+1), false},
+		{"bad id", false},
+		{"bad\x1b[31mid", false},
+		{"", false},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(DefaultRequestIDHeader, tc.id)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		got := rec.Header().Get(DefaultRequestIDHeader)
+		if tc.keep && got != tc.id {
+			t.Fatalf("request id %q replaced by %q", tc.id, got)
+		}
+		if !tc.keep && (got == tc.id || !validRequestID(got)) {
+			t.Fatalf("request id %q not regenerated: %q", tc.id, got)
+		}
+	}
+}
+
+func TestHTTPMiddlewareLogsPanic(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	h := HTTPMiddleware(l, HTTPOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic was swallowed")
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	}()
+
+	if got := buf.String(); !strings.Contains(got, "[ERROR]") || !strings.Contains(got, "status=500") || !strings.Contains(got, "path=/panic") {
+		t.Fatalf("access log = %q", got)
+	}
+}
+
+func TestHTTPMiddlewareSkip(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	var injected *LogsLogger
+	h := HTTPMiddleware(l, HTTPOptions{
+		SkipPaths: []string{"/healthz"},
+		Skip:      func(r *http.Request) bool { return r.Method == http.MethodOptions },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		injected = FromContext(r.Context())
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if injected != l {
+		t.Fatal("skipped request did not get the base logger")
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodOptions, "/api", nil))
+	if buf.Len() != 0 {
+		t.Fatalf("skipped requests logged: %q", buf.String())
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api", nil))
+	if injected == l || !strings.Contains(buf.String(), "path=/api") {
+		t.Fatalf("request not logged with a request logger: %q", buf.String())
+	}
+}
@@ -0,0 +1,259 @@
+package logs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// AtomicLevel 可以并发读写的日志级别，读取不加锁。
+// 多个日志器可以通过 SetAtomicLevel 共享同一个 AtomicLevel，修改后同时生效；With 创建的子日志器与父日志器共享级别
+type AtomicLevel struct {
+	v atomic.Int32
+}
+
+// NewAtomicLevel 创建 AtomicLevel
+func NewAtomicLevel(level LogLevel) *AtomicLevel {
+	a := &AtomicLevel{}
+	a.v.Store(int32(level))
+	return a
+}
+
+// Level 返回当前级别
+func (a *AtomicLevel) Level() LogLevel {
+	return LogLevel(a.v.Load())
+}
+
+// SetLevel 修改级别
+func (a *AtomicLevel) SetLevel(level LogLevel) error {
+	if level < LogLevelDebug || level > LogLevelPanic {
+		return errors.New("invalid log level")
+	}
+	a.v.Store(int32(level))
+	return nil
+}
+
+// Enabled 该级别的日志是否会输出
+func (a *AtomicLevel) Enabled(level LogLevel) bool {
+	return level >= a.Level()
+}
+
+func (a *AtomicLevel) String() string {
+	return a.Level().String()
+}
+
+// ServeHTTP 查询（GET）或修改（PUT/POST）该级别，见 LevelHandler
+func (a *AtomicLevel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveLevel(w, r, "", a.Level, a.SetLevel)
+}
+
+var (
+	levelsMu   sync.RWMutex
+	levels     = make(map[string]*AtomicLevel) // RegisterLevel 注册的级别，供 LevelHandler 按名称查找
+	nameLevels = make(map[string]*AtomicLevel) // SetNameLevel、SetNameLevels 设置的名称级别，供具名日志器按名称前缀查找
+)
+
+// RegisterLevel 以 name 注册级别，之后可以通过 LevelHandler 的 ?name= 参数查询和修改；同名的级别会被替换。
+// 注册的级别只用于 LevelHandler，不影响 Named 创建的日志器（名称级别见 SetNameLevel）
+func RegisterLevel(name string, level *AtomicLevel) error {
+	if name == "" {
+		return errors.New("level name cannot be empty")
+	}
+	if level == nil {
+		return errors.New("level cannot be nil")
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	levels[name] = level
+	return nil
+}
+
+// UnregisterLevel 取消注册
+func UnregisterLevel(name string) {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	delete(levels, name)
+}
+
+// lookupLevel 按名称查找级别，先查 RegisterLevel 注册的级别，再查名称级别；name 为空时返回全局日志器的级别
+func lookupLevel(name string) (*AtomicLevel, bool) {
+	if name == "" {
+		return GetAtomicLevel(), true
+	}
+
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	if a, ok := levels[name]; ok {
+		return a, true
+	}
+	a, ok := nameLevels[name]
+	return a, ok
+}
+
+// LevelHandler 返回用于运行时查询和修改日志级别的 http.Handler：
+//
+//	GET  /?name=db                      返回 {"name":"db","level":"debug"}，不带 name 时为全局日志器
+//	PUT  /?name=db  {"level":"warn"}    修改级别，也接受表单或查询参数 level=warn
+//
+// POST 与 PUT 相同。name 既没有注册也没有名称级别时，PUT 通过 SetNameLevel 设置名称级别，
+// GET 返回生效的级别：父名称的名称级别，都没有时为全局日志器的级别
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		if a, ok := lookupLevel(name); ok {
+			serveLevel(w, r, name, a.Level, a.SetLevel)
+			return
+		}
+		serveLevel(w, r, name, func() LogLevel {
+			return effectiveNameLevel(name)
+		}, func(level LogLevel) error {
+			return SetNameLevel(name, level)
+		})
+	})
+}
+
+// effectiveNameLevel 返回名称为 name 的日志器生效的名称级别，没有时返回全局日志器的级别
+func effectiveNameLevel(name string) LogLevel {
+	levelsMu.RLock()
+	a := matchNameLevel(name)
+	levelsMu.RUnlock()
+	if a == nil {
+		a = GetAtomicLevel()
+	}
+	return a.Level()
+}
+
+type levelPayload struct {
+	Name  string `json:"name,omitempty"`
+	Level string `json:"level"`
+}
+
+// serveLevel 处理级别的查询和修改，get、set 读取和修改级别
+func serveLevel(w http.ResponseWriter, r *http.Request, name string, get func() LogLevel, set func(LogLevel) error) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		level, err := levelFromRequest(r)
+		if err != nil {
+			writeLevelError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := set(level); err != nil {
+			writeLevelError(w, http.StatusBadRequest, err)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeLevelError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(levelPayload{Name: name, Level: get().String()})
+}
+
+// levelFromRequest 从 JSON 请求体 {"level":"debug"} 或表单/查询参数 level=debug 中解析级别
+func levelFromRequest(r *http.Request) (LogLevel, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var body struct {
+			Level interface{} `json:"level"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return 0, fmt.Errorf("invalid request body: %v", err)
+		}
+		if body.Level == nil {
+			return 0, errors.New("level is required")
+		}
+		return confLevel(body.Level)
+	}
+
+	value := r.FormValue("level")
+	if value == "" {
+		return 0, errors.New("level is required")
+	}
+	return ParseLogLevel(value)
+}
+
+func writeLevelError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// 日志器的级别 ---------------------------------------------------------------------
+// enabled 该级别的日志是否会输出，名称级别优先于日志器自身的级别
+func (l *LogsLogger) enabled(level LogLevel) bool {
+	if n := l.named; n != nil {
+		if a := n.nameLevel(); a != nil {
+			return level >= a.Level()
+		}
+	}
+	if a := l.level.Load(); a != nil {
+		return level >= a.Level()
+	}
+	return level >= LogLevel(l.outputs().conf.Level)
+}
+
+// currentLevel 返回当前级别，AtomicLevel 可能被直接修改，因此以它为准
+func (l *LogsLogger) currentLevel() LogLevel {
+	if a := l.level.Load(); a != nil {
+		return a.Level()
+	}
+	return LogLevel(l.outputs().conf.Level)
+}
+
+// GetAtomicLevel 返回日志器使用的 AtomicLevel，修改它会立即生效
+func (l *LogsLogger) GetAtomicLevel() *AtomicLevel {
+	mu2.Lock()
+	defer mu2.Unlock()
+
+	return l.atomicLevel()
+}
+
+// atomicLevel 返回日志器的 AtomicLevel，没有时按配置创建；调用方需持有锁
+func (l *LogsLogger) atomicLevel() *AtomicLevel {
+	if a := l.level.Load(); a != nil {
+		return a
+	}
+	a := NewAtomicLevel(LogLevel(l.outputs().conf.Level))
+	l.level.Store(a)
+	return a
+}
+
+// SetAtomicLevel 让日志器使用给定的 AtomicLevel，用于多个日志器共享级别
+func (l *LogsLogger) SetAtomicLevel(level *AtomicLevel) error {
+	mu2.Lock()
+	defer mu2.Unlock()
+
+	if level == nil {
+		return errors.New("level cannot be nil")
+	}
+	l.level.Store(level)
+	l.logConf.Level = int(level.Level())
+	return nil
+}
+
+// 全局日志器的级别
+func GetAtomicLevel() *AtomicLevel {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return globalLogger.atomicLevel()
+}
+
+func SetAtomicLevel(level *AtomicLevel) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if level == nil {
+		return errors.New("level cannot be nil")
+	}
+	globalLogger.level.Store(level)
+	globalLogger.logConf.Level = int(level.Level())
+	return nil
+}
@@ -0,0 +1,98 @@
+package logs
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// 与 SetAtomicLevel 并发写日志，在 -race 下检查级别的读取
+func TestSetAtomicLevelConcurrent(t *testing.T) {
+	l, err := NewLogger(LogConf{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetOutput(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	child := l.With("k", "v")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			if err := l.SetAtomicLevel(NewAtomicLevel(LogLevelWarn)); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			l.Info("info")
+			child.Info("info")
+		}
+	}()
+	wg.Wait()
+
+	if l.GetAtomicLevel().Level() != LogLevelWarn {
+		t.Fatalf("level = %v, want warn", l.GetAtomicLevel().Level())
+	}
+}
+
+// LevelHandler 可以查询和修改没有注册过的具名日志器的级别
+func TestLevelHandlerNamed(t *testing.T) {
+	t.Cleanup(func() { SetNameLevels("") })
+	l, buf := newBufferLogger(t)
+	db := l.Named("db")
+	h := LevelHandler()
+	global := GetAtomicLevel().Level().String()
+
+	for _, tc := range []struct {
+		method, target, contentType, body string
+		status                            int
+		level                             string
+	}{
+		{http.MethodGet, "/?name=db", "", "", http.StatusOK, global},
+		{http.MethodPut, "/?name=db", "application/x-www-form-urlencoded", "level=error", http.StatusOK, "error"},
+		{http.MethodGet, "/?name=db", "", "", http.StatusOK, "error"},
+		{http.MethodGet, "/?name=db.pool", "", "", http.StatusOK, "error"}, // 父名称的级别
+		{http.MethodPut, "/?name=db", "application/json", `{"level":"verbose"}`, http.StatusBadRequest, ""},
+		{http.MethodPut, "/?name=db", "application/json", `{"level":`, http.StatusBadRequest, ""},
+		{http.MethodPut, "/?name=db", "", "", http.StatusBadRequest, ""},
+		{http.MethodDelete, "/?name=db", "", "", http.StatusMethodNotAllowed, ""},
+		{http.MethodGet, "/?name=db", "", "", http.StatusOK, "error"},
+	} {
+		req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+		if tc.contentType != "" {
+			req.Header.Set("Content-Type", tc.contentType)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.status {
+			t.Fatalf("%s %s %q: status = %d, want %d", tc.method, tc.target, tc.body, rec.Code, tc.status)
+		}
+		if tc.level == "" {
+			continue
+		}
+		var got levelPayload
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Level != tc.level {
+			t.Fatalf("%s %s: level = %q, want %q", tc.method, tc.target, got.Level, tc.level)
+		}
+	}
+
+	db.Warn("filtered")
+	db.Error("logged")
+	if got := buf.String(); strings.Contains(got, "filtered") || !strings.Contains(got, "logged") {
+		t.Fatalf("output = %q", got)
+	}
+}
@@ -2,53 +2,81 @@ package logs
 
 import (
 	"fmt"
-	"os"
 )
 
 // LogsLogger 的 output 方法
 func (l *LogsLogger) Debug(v ...interface{}) {
-	outputLog(l, LogLevelDebug, 3, "", v...)
+	outputLog(l, LogLevelDebug, 3, "", v, nil)
 }
 func (l *LogsLogger) Debugf(format string, v ...interface{}) {
-	outputLog(l, LogLevelDebug, 3, format, v...)
+	outputLog(l, LogLevelDebug, 3, format, v, nil)
 }
 
 func (l *LogsLogger) Info(v ...interface{}) {
-	outputLog(l, LogLevelInfo, 3, "", v...)
+	outputLog(l, LogLevelInfo, 3, "", v, nil)
 }
 func (l *LogsLogger) Infof(format string, v ...interface{}) {
-	outputLog(l, LogLevelInfo, 3, format, v...)
+	outputLog(l, LogLevelInfo, 3, format, v, nil)
 }
 
 func (l *LogsLogger) Warn(v ...interface{}) {
-	outputLog(l, LogLevelWarn, 3, "", v...)
+	outputLog(l, LogLevelWarn, 3, "", v, nil)
 }
 func (l *LogsLogger) Warnf(format string, v ...interface{}) {
-	outputLog(l, LogLevelWarn, 3, format, v...)
+	outputLog(l, LogLevelWarn, 3, format, v, nil)
 }
 
 func (l *LogsLogger) Error(v ...interface{}) {
-	outputLog(l, LogLevelError, 3, "", v...)
+	outputLog(l, LogLevelError, 3, "", v, nil)
 }
 func (l *LogsLogger) Errorf(format string, v ...interface{}) {
-	outputLog(l, LogLevelError, 3, format, v...)
+	outputLog(l, LogLevelError, 3, format, v, nil)
 }
 
 func (l *LogsLogger) Fatal(v ...interface{}) {
-	outputLog(l, LogLevelFatal, 3, "", v...)
-	os.Exit(1)
+	outputLog(l, LogLevelFatal, 3, "", v, nil)
+	exitAfterFlush(1)
 }
 func (l *LogsLogger) Fatalf(format string, v ...interface{}) {
-	outputLog(l, LogLevelFatal, 3, format, v...)
-	os.Exit(1)
+	outputLog(l, LogLevelFatal, 3, format, v, nil)
+	exitAfterFlush(1)
 }
 
 func (l *LogsLogger) Panic(v ...interface{}) {
-	outputLog(l, LogLevelPanic, 3, "", v...)
+	outputLog(l, LogLevelPanic, 3, "", v, nil)
+	flushQueues()
 	panic(fmt.Sprint(v...))
 }
 
 func (l *LogsLogger) Panicf(format string, v ...interface{}) {
-	outputLog(l, LogLevelPanic, 3, format, v...)
+	outputLog(l, LogLevelPanic, 3, format, v, nil)
+	flushQueues()
 	panic(fmt.Sprintf(format, v...))
 }
+
+func (l *LogsLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	outputLog(l, LogLevelDebug, 3, "", []interface{}{msg}, toFields(keysAndValues))
+}
+
+func (l *LogsLogger) Infow(msg string, keysAndValues ...interface{}) {
+	outputLog(l, LogLevelInfo, 3, "", []interface{}{msg}, toFields(keysAndValues))
+}
+
+func (l *LogsLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	outputLog(l, LogLevelWarn, 3, "", []interface{}{msg}, toFields(keysAndValues))
+}
+
+func (l *LogsLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	outputLog(l, LogLevelError, 3, "", []interface{}{msg}, toFields(keysAndValues))
+}
+
+func (l *LogsLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	outputLog(l, LogLevelFatal, 3, "", []interface{}{msg}, toFields(keysAndValues))
+	exitAfterFlush(1)
+}
+
+func (l *LogsLogger) Panicw(msg string, keysAndValues ...interface{}) {
+	outputLog(l, LogLevelPanic, 3, "", []interface{}{msg}, toFields(keysAndValues))
+	flushQueues()
+	panic(msg)
+}
@@ -6,8 +6,7 @@ import (
 	"io"
 	"log"
 	"os"
-
-	"gopkg.in/natefinch/lumberjack.v2"
+	"time"
 )
 
 func (l *LogsLogger) initLoggers(output io.Writer) {
@@ -26,7 +25,7 @@ func (l *LogsLogger) initLoggers(output io.Writer) {
 		}
 	}
 
-	var multiWriter io.Writer
+	multiWriter := output
 	if output != os.Stderr {
 		multiWriter = io.MultiWriter(os.Stderr, output)
 	}
@@ -38,47 +37,69 @@ func (l *LogsLogger) initLoggers(output io.Writer) {
 	l.errorL = log.New(output, "[ERROR] ", flags)
 	l.fatalL = log.New(multiWriter, "[FATAL] ", flags)
 	l.panicL = log.New(multiWriter, "[PANIC] ", flags)
+	l.publish()
 }
 
 func (l *LogsLogger) initFileLog(logFilePath string) {
 
-	if fileLogger == nil {
-		fileLogger = &lumberjack.Logger{}
-	}
-	fileLogger.Filename = logFilePath
-	fileLogger.MaxSize = l.logConf.MaxSize
-	fileLogger.MaxBackups = l.logConf.MaxBackups
-	fileLogger.MaxAge = l.logConf.KeepDays
-	fileLogger.Compress = l.logConf.Compress
+	// 每个日志器持有自己的文件写入器引用，相同路径的日志器共享同一个写入器
+	handle := acquireFileWriter(logFilePath, l.logConf, l.rotateHooks)
+	l.fileHandle.release()
+	l.fileHandle = handle
+
+	// 文件中不写入颜色代码
+	fileWriter := &noColorWriter{w: handle}
 
-	l.output = fileLogger
+	l.output = fileWriter
 	// 重新初始化所有日志器
-	l.initLoggers(fileLogger)
+	l.initLoggers(fileWriter)
 }
 
 func (l *LogsLogger) initMultiWriter(logFilePath string) {
 
-	if fileLogger == nil {
-		fileLogger = &lumberjack.Logger{}
-	}
-	fileLogger.Filename = logFilePath
-	fileLogger.MaxSize = l.logConf.MaxSize
-	fileLogger.MaxBackups = l.logConf.MaxBackups
-	fileLogger.MaxAge = l.logConf.KeepDays
-	fileLogger.Compress = l.logConf.Compress
+	handle := acquireFileWriter(logFilePath, l.logConf, l.rotateHooks)
+	l.fileHandle.release()
+	l.fileHandle = handle
 
 	// 创建一个同时写入控制台和文件的 Writer
-	multiWriter := io.MultiWriter(os.Stdout, fileLogger)
+	multiWriter := io.MultiWriter(os.Stdout, &noColorWriter{w: handle})
 
 	l.output = multiWriter
 	// 重新初始化所有日志器
 	l.initLoggers(multiWriter)
 }
 
+// SetUp 按配置初始化日志器，配置无效时返回错误，日志器保持原样
 func (l *LogsLogger) SetUp(logConf LogConf) error {
 	mu2.Lock()
-	defer mu2.Unlock()
+	l.own()
+	s, err := l.prepareSetUp(logConf)
+	if err != nil {
+		mu2.Unlock()
+		return err
+	}
+	r := l.applySetUp(s)
+	mu2.Unlock()
+
+	r.release()
+	return nil
+}
+
+// setUpState SetUp 要替换的日志器状态，全部校验、创建成功后才应用到日志器
+type setUpState struct {
+	conf       LogConf
+	encoder    RecordEncoder
+	location   *time.Location
+	nameLevels map[string]LogLevel // 为 nil 时保留已有的名称级别
+	hooks      *rotateHooks
+	sinks      []*Sink // 设置了 sinks 时的输出目标，不包括路由
+	routes     []*Sink
+	flags      int
+	strategy   logWriteStrategy
+}
 
+// prepareSetUp 补全默认值、校验配置并打开所有日志文件，不修改日志器；调用方需持有锁
+func (l *LogsLogger) prepareSetUp(logConf LogConf) (*setUpState, error) {
 	// 检查日志配置是否有效
 	if logConf.Mode == "" {
 		logConf.Mode = defaultLogConf.Mode
@@ -102,33 +123,98 @@ func (l *LogsLogger) SetUp(logConf LogConf) error {
 		logConf.Path = defaultLogConf.Path
 	}
 
-	l.logConf = logConf
-	l.logFlags = LogFlagsCommon
-	l.hasRootFilePrefix = false
-	l.logWriteStrategy = LoggingSync // 默认同步模式
+	if len(logConf.Sinks) == 0 && (logConf.Mode == "file" || logConf.Mode == "both") {
+		if logConf.Path == "" {
+			return nil, errors.New("log path is required")
+		}
+	}
 
-	if l.logConf.Mode == "file" || l.logConf.Mode == "both" {
-		if l.logConf.Path == "" {
-			return errors.New("log path is required")
+	s := &setUpState{conf: logConf, hooks: l.rotateHooks, flags: LogFlagsCommon, strategy: LoggingSync}
+	if s.hooks == nil {
+		s.hooks = &rotateHooks{}
+	}
+
+	// 编码
+	encoder, err := newEncoder(logConf.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	s.encoder = encoder
+
+	// 异步队列配置
+	if err := validOverflowPolicy(logConf.OverflowPolicy); err != nil {
+		return nil, err
+	}
+
+	// 时间格式和时区
+	if err := validTimeFormat(logConf.TimeFormat); err != nil {
+		return nil, err
+	}
+	if s.location, err = loadTimeZone(logConf.TimeZone); err != nil {
+		return nil, err
+	}
+
+	// 切割策略
+	if _, err := validateRotation(logConf.Rotation, logConf.FilePattern, logConf.Path); err != nil {
+		return nil, err
+	}
+
+	// 日志级别
+	if LogLevel(logConf.Level) < LogLevelDebug || LogLevel(logConf.Level) > LogLevelPanic {
+		return nil, errors.New("invalid log level")
+	}
+
+	// 名称级别，为空时保留已有的设置
+	if logConf.NameLevels != "" {
+		if s.nameLevels, err = parseNameLevels(logConf.NameLevels); err != nil {
+			return nil, err
 		}
 	}
 
-	// 设置编码
-	switch logConf.Encoding {
-	case LogEncodingPlain:
-		l.encoder = &PlainEncoder{}
-	case LogEncodingJSON:
-		l.encoder = &JsonEncoder{}
-	default:
-		return fmt.Errorf("unsupported log encoding: %s", logConf.Encoding)
+	// 按级别分流的日志文件，与主输出同时写入
+	if s.routes, err = openRoutes(logConf, s.hooks); err != nil {
+		return nil, err
 	}
 
-	// 设置日志级别
-	if LogLevel(logConf.Level) < LogLevelDebug {
-		return errors.New("invalid log level")
+	// 设置了 sinks 时 Mode 不再生效
+	if len(logConf.Sinks) > 0 {
+		if s.sinks, err = openSinks(logConf, s.hooks); err != nil {
+			releaseSinks(s.routes)
+			return nil, err
+		}
 	}
+	return s, nil
+}
 
-	// currentLogLevel = LogLevel(logConf.Level)
+// applySetUp 用准备好的状态替换日志器的配置和输出，不会失败；调用方需持有锁。
+// 返回旧的队列、文件和输出目标，调用方解锁后释放，旧队列中的日志仍写入旧的输出
+func (l *LogsLogger) applySetUp(s *setUpState) *retiredOutput {
+	r := &retiredOutput{owner: l, queue: l.queue, handle: l.fileHandle}
+	l.queue = nil
+	l.fileHandle = nil
+	if ss := l.sinks.Load(); ss != nil && ss.owner == l && !ss.released.Load() {
+		r.sinks = ss.load()
+		for _, sink := range r.sinks {
+			sink.retain()
+		}
+	}
+
+	l.logConf = s.conf
+	l.rotateHooks = s.hooks
+	l.logFlags = s.flags
+	l.hasRootFilePrefix = false
+	l.logWriteStrategy = s.strategy
+	l.encoder = s.encoder
+	l.timeLocation = s.location
+
+	if a := l.level.Load(); a == nil {
+		l.level.Store(NewAtomicLevel(LogLevel(s.conf.Level)))
+	} else {
+		a.SetLevel(LogLevel(s.conf.Level))
+	}
+	if s.nameLevels != nil {
+		replaceNameLevels(s.nameLevels)
+	}
 
 	// 获取项目根目录
 	projectRootOnce.Do(func() {
@@ -140,16 +226,29 @@ func (l *LogsLogger) SetUp(logConf LogConf) error {
 	})
 
 	// 初始化输出
-	switch l.logConf.Mode {
-	case "file":
-		l.initFileLog(l.logConf.Path)
-	case "both":
-		l.initMultiWriter(l.logConf.Path)
-	default:
+	if len(s.conf.Sinks) > 0 {
+		l.fileHandle.release()
+		l.fileHandle = nil
+		l.output = os.Stdout
 		l.initLoggers(os.Stdout)
+		l.setSinks(append(s.sinks, s.routes...))
+	} else {
+		switch s.conf.Mode {
+		case "file":
+			l.initFileLog(s.conf.Path)
+		case "both":
+			l.initMultiWriter(s.conf.Path)
+		default:
+			l.initLoggers(os.Stdout)
+		}
+		l.setSinks(append([]*Sink{modeSink(s.conf.Mode)}, s.routes...))
 	}
 
-	return nil
+	if s.strategy == LoggingAsync {
+		l.restartQueue()
+	}
+	l.publish()
+	return r
 }
 
 // SetOutput 设置日志输出位置，自动更新Mode
@@ -161,6 +260,7 @@ func (l *LogsLogger) SetOutput(writer io.Writer) error {
 		return errors.New("writer cannot be nil")
 	}
 
+	l.own()
 	l.output = writer
 
 	mode := LogModeConsole
@@ -203,26 +303,78 @@ func (l *LogsLogger) SetOutput(writer io.Writer) error {
 	fmt.Println("mode：", mode)
 
 	l.logConf.Mode = mode
-	initLoggers(l.output)
+	// 不再使用之前的日志文件和输出目标
+	l.fileHandle.release()
+	l.fileHandle = nil
+	l.initLoggers(l.output)
+	l.logConf.Sinks = nil
+	l.setSinks(append([]*Sink{modeSink(mode)}, l.routeSinks()...))
 
 	return nil
 }
 
 // 设置编码
 func (l *LogsLogger) SetEncoding(encoding string) error {
-	// LogEncodingJSON、LOgEncodingPlain
+	// LogEncodingJSON、LogEncodingPlain、LogEncodingLogfmt
 	mu2.Lock()
 	defer mu2.Unlock()
+
+	encoder, err := newEncoder(encoding)
+	if err != nil {
+		return err
+	}
+	l.own()
 	l.logConf.Encoding = encoding
+	l.encoder = encoder
+	l.publish()
+	return nil
+}
 
-	switch encoding {
-	case LogEncodingPlain:
-		l.encoder = &PlainEncoder{}
-	case LogEncodingJSON:
-		l.encoder = &JsonEncoder{}
-	default:
-		return fmt.Errorf("unsupported log encoding: %s", encoding)
+// SetEncoder 设置自定义编码器，旧的 Encoder 会通过 AdaptEncoder 适配
+func (l *LogsLogger) SetEncoder(encoder Encoder) error {
+	if encoder == nil {
+		return errors.New("encoder cannot be nil")
+	}
+	return l.SetRecordEncoder(AdaptEncoder(encoder))
+}
+
+// SetRecordEncoder 设置接收完整日志记录的编码器
+func (l *LogsLogger) SetRecordEncoder(encoder RecordEncoder) error {
+	mu2.Lock()
+	defer mu2.Unlock()
+
+	if encoder == nil {
+		return errors.New("encoder cannot be nil")
+	}
+	l.own()
+	l.encoder = encoder
+	l.publish()
+	return nil
+}
+
+// SetTimeFormat 设置时间格式：Go 时间布局或预设（rfc3339、rfc3339nano、iso8601、epoch、epoch_ms 等），
+// 传入空字符串时恢复为由 Ldate、Ltime 等标志控制
+func (l *LogsLogger) SetTimeFormat(format string) {
+	mu2.Lock()
+	defer mu2.Unlock()
+	l.own()
+	l.logConf.TimeFormat = format
+	l.publish()
+}
+
+// SetTimeZone 设置日志时间的时区，如 "UTC"、"Asia/Shanghai"
+func (l *LogsLogger) SetTimeZone(name string) error {
+	mu2.Lock()
+	defer mu2.Unlock()
+
+	loc, err := loadTimeZone(name)
+	if err != nil {
+		return err
 	}
+	l.own()
+	l.logConf.TimeZone = name
+	l.timeLocation = loc
+	l.publish()
 	return nil
 }
 
@@ -230,9 +382,15 @@ func (l *LogsLogger) SetEncoding(encoding string) error {
 func (l *LogsLogger) SetMaxSize(maxSize int) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.logConf.MaxSize = maxSize
+	l.publish()
 
 	// 重新初始化日志器以应用新设置
+	l.applySinkRotation()
+	if len(l.logConf.Sinks) > 0 {
+		return // 设置了 sinks 时 Mode 不再生效
+	}
 	if l.logConf.Mode == "file" {
 		l.initFileLog(l.logConf.Path)
 	} else if l.logConf.Mode == "both" {
@@ -244,9 +402,15 @@ func (l *LogsLogger) SetMaxSize(maxSize int) {
 func (l *LogsLogger) SetMaxAge(maxAge int) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.logConf.KeepDays = maxAge
+	l.publish()
 
 	// 重新初始化日志器以应用新设置
+	l.applySinkRotation()
+	if len(l.logConf.Sinks) > 0 {
+		return // 设置了 sinks 时 Mode 不再生效
+	}
 	if l.logConf.Mode == "file" {
 		l.initFileLog(l.logConf.Path)
 	} else if l.logConf.Mode == "both" {
@@ -258,8 +422,33 @@ func (l *LogsLogger) SetMaxAge(maxAge int) {
 func (l *LogsLogger) SetMaxBackups(maxBackups int) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.logConf.MaxBackups = maxBackups
+	l.publish()
 
+	l.applySinkRotation()
+	if len(l.logConf.Sinks) > 0 {
+		return // 设置了 sinks 时 Mode 不再生效
+	}
+	if l.logConf.Mode == "file" {
+		l.initFileLog(l.logConf.Path)
+	} else if l.logConf.Mode == "both" {
+		l.initMultiWriter(l.logConf.Path)
+	}
+}
+
+// 设置是否压缩切割后的日志文件
+func (l *LogsLogger) SetCompress(compress bool) {
+	mu2.Lock()
+	defer mu2.Unlock()
+	l.own()
+	l.logConf.Compress = compress
+	l.publish()
+
+	l.applySinkRotation()
+	if len(l.logConf.Sinks) > 0 {
+		return // 设置了 sinks 时 Mode 不再生效
+	}
 	if l.logConf.Mode == "file" {
 		l.initFileLog(l.logConf.Path)
 	} else if l.logConf.Mode == "both" {
@@ -276,6 +465,10 @@ func (l *LogsLogger) SetLogLevel(level LogLevel) error {
 	}
 
 	l.logConf.Level = int(level)
+	if a := l.level.Load(); a != nil {
+		return a.SetLevel(level)
+	}
+	l.level.Store(NewAtomicLevel(level))
 	return nil
 }
 
@@ -291,6 +484,8 @@ func (l *LogsLogger) SetFlags(flags int) error {
 		return errors.New("invalid flags value")
 	}
 
+	l.own()
+
 	// 检查是否设置了 Ldate、Ltime 或 Lmicroseconds 标志
 	if flags&(Ldate|Ltime|Lmicroseconds) == 0 {
 		// 如果没有设置日期、时间或微秒，设置默认的 Ldate | Ltime
@@ -319,20 +514,33 @@ func (l *LogsLogger) SetFlags(flags int) error {
 	l.errorL.SetFlags(flags)
 	l.fatalL.SetFlags(flags)
 	l.panicL.SetFlags(flags)
+	l.publish()
 	return nil
 }
 
 // 设置日志同步还是异步
 func (l *LogsLogger) SetLogWriteStrategy(strategy logWriteStrategy) {
 	mu2.Lock()
-	defer mu2.Unlock()
+	l.own()
 	l.logWriteStrategy = strategy
+
+	// 按配置创建异步队列，配置有变化时重建
+	var r *retiredOutput
+	if strategy == LoggingAsync {
+		if l.queue == nil || !l.queue.matches(l.logConf) {
+			r = l.restartQueue()
+		}
+	}
+	l.publish()
+	mu2.Unlock()
+	r.release()
 }
 
 // 设置前缀
 func (l *LogsLogger) SetPrefix(prefix string) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.debugL.SetPrefix("[DEBUG] " + prefix)
 	l.infoL.SetPrefix("[INFO] " + prefix)
 	l.warnL.SetPrefix("[WARN] " + prefix)
@@ -344,71 +552,83 @@ func (l *LogsLogger) SetPrefix(prefix string) {
 func (l *LogsLogger) SetDebugPrefixWithoutDefaultPrefix(prefix string) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.debugL.SetPrefix(prefix)
 }
 
 func (l *LogsLogger) SetDebugPrefix(prefix string) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.debugL.SetPrefix("[DEBUG] " + prefix)
 }
 
 func (l *LogsLogger) SetInfoPrefixWithoutDefaultPrefix(prefix string) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.infoL.SetPrefix(prefix)
 }
 
 func (l *LogsLogger) SetInfoPrefix(prefix string) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.infoL.SetPrefix("[INFO] " + prefix)
 }
 
 func (l *LogsLogger) SetWarnPrefixWithoutDefaultPrefix(prefix string) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.warnL.SetPrefix(prefix)
 }
 
 func (l *LogsLogger) SetWarnPrefix(prefix string) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.warnL.SetPrefix("[WARN] " + prefix)
 }
 
 func (l *LogsLogger) SetErrorPrefixWithoutDefaultPrefix(prefix string) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.errorL.SetPrefix(prefix)
 }
 
 func (l *LogsLogger) SetErrorPrefix(prefix string) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.errorL.SetPrefix("[ERROR] " + prefix)
 }
 
 func (l *LogsLogger) SetFatalPrefixWithoutDefaultPrefix(prefix string) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.fatalL.SetPrefix(prefix)
 }
 
 func (l *LogsLogger) SetFatalPrefix(prefix string) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.fatalL.SetPrefix("[FATAL] " + prefix)
 }
 
 func (l *LogsLogger) SetPanicPrefixWithoutDefaultPrefix(prefix string) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.panicL.SetPrefix(prefix)
 }
 
 func (l *LogsLogger) SetPanicPrefix(prefix string) {
 	mu2.Lock()
 	defer mu2.Unlock()
+	l.own()
 	l.panicL.SetPrefix("[PANIC] " + prefix)
 }
@@ -0,0 +1,158 @@
+package logs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// levelsGen 名称级别的版本号，添加或删除名称时递增，使各日志器缓存的级别失效
+var levelsGen atomic.Uint64
+
+// nameLevelCache 具名日志器缓存的级别查找结果
+type nameLevelCache struct {
+	gen   uint64
+	level *AtomicLevel // 匹配到的名称级别，为空时使用日志器自身的级别
+}
+
+// loggerName 具名日志器的名称以及级别查找缓存，With 创建的子日志器共享它
+type loggerName struct {
+	name  string
+	cache atomic.Pointer[nameLevelCache]
+}
+
+// Named 创建具名子日志器，名称用 "." 连接父日志器的名称，如 Named("db").Named("pool") 的名称为 "db.pool"。
+// 名称会输出在每条日志中；通过 SetNameLevel、SetNameLevels 为名称前缀设置的级别优先于日志器自身的级别
+func (l *LogsLogger) Named(name string) *LogsLogger {
+	child := l.withFields(nil)
+	if name == "" {
+		return child
+	}
+	if l.named != nil {
+		name = l.named.name + "." + name
+	}
+	child.named = &loggerName{name: name}
+	return child
+}
+
+// Name 返回日志器的名称，未命名时为空
+func (l *LogsLogger) Name() string {
+	if l.named == nil {
+		return ""
+	}
+	return l.named.name
+}
+
+// nameLevel 返回名称对应的级别：匹配最长的已注册名称前缀（按 "." 分段），没有匹配时返回 nil。
+// 结果按注册表版本号缓存，热路径上只有两次原子读取
+func (n *loggerName) nameLevel() *AtomicLevel {
+	gen := levelsGen.Load()
+	if c := n.cache.Load(); c != nil && c.gen == gen {
+		return c.level
+	}
+
+	levelsMu.RLock()
+	level := matchNameLevel(n.name)
+	levelsMu.RUnlock()
+
+	n.cache.Store(&nameLevelCache{gen: gen, level: level})
+	return level
+}
+
+// matchNameLevel 查找 name 及其各级父名称（db.pool -> db）中最先设置的名称级别，调用方需持有 levelsMu
+func matchNameLevel(name string) *AtomicLevel {
+	for {
+		if a, ok := nameLevels[name]; ok {
+			return a
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return nil
+		}
+		name = name[:i]
+	}
+}
+
+// SetNameLevel 设置名称前缀的级别，对该名称及其子名称（如 "db" 对 "db.pool"）的日志器生效
+func SetNameLevel(name string, level LogLevel) error {
+	if name == "" {
+		return fmt.Errorf("level name cannot be empty")
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	if a, ok := nameLevels[name]; ok {
+		return a.SetLevel(level)
+	}
+	a := &AtomicLevel{}
+	if err := a.SetLevel(level); err != nil {
+		return err
+	}
+	nameLevels[name] = a
+	levelsGen.Add(1)
+	return nil
+}
+
+// SetNameLevels 按 "db=debug,http=warn" 格式替换所有名称级别，传入空字符串时清空。
+// 不影响 RegisterLevel 注册的级别
+func SetNameLevels(spec string) error {
+	parsed, err := parseNameLevels(spec)
+	if err != nil {
+		return err
+	}
+	replaceNameLevels(parsed)
+	return nil
+}
+
+// replaceNameLevels 用解析后的名称级别替换所有名称级别，已有名称的 AtomicLevel 保持不变
+func replaceNameLevels(parsed map[string]LogLevel) {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	for name := range nameLevels {
+		if _, ok := parsed[name]; !ok {
+			delete(nameLevels, name)
+		}
+	}
+	for name, level := range parsed {
+		if a, ok := nameLevels[name]; ok {
+			a.SetLevel(level)
+		} else {
+			nameLevels[name] = NewAtomicLevel(level)
+		}
+	}
+	levelsGen.Add(1)
+}
+
+// NameLevels 返回当前所有名称级别，格式与 SetNameLevels 相同，按名称排序
+func NameLevels() string {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+
+	pairs := make([]string, 0, len(nameLevels))
+	for name, a := range nameLevels {
+		pairs = append(pairs, name+"="+a.Level().String())
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// parseNameLevels 解析 "db=debug,http=warn"，也接受空格分隔
+func parseNameLevels(spec string) (map[string]LogLevel, error) {
+	parsed := make(map[string]LogLevel)
+	for _, item := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == ' ' }) {
+		name, value, ok := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid name level %q, expected name=level", item)
+		}
+		level, err := ParseLogLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name level %q: %v", item, err)
+		}
+		parsed[name] = level
+	}
+	return parsed, nil
+}
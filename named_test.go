@@ -0,0 +1,58 @@
+package logs
+
+import (
+	"testing"
+)
+
+func TestSetNameLevelsKeepsRegisteredLevels(t *testing.T) {
+	registered := NewAtomicLevel(LogLevelWarn)
+	if err := RegisterLevel("db", registered); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		UnregisterLevel("db")
+		SetNameLevels("")
+	})
+
+	if err := SetNameLevels("http=debug"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetNameLevels(""); err != nil {
+		t.Fatal(err)
+	}
+	if a, ok := lookupLevel("db"); !ok || a != registered {
+		t.Fatalf("registered level removed by SetNameLevels")
+	}
+	if got := NameLevels(); got != "" {
+		t.Fatalf("NameLevels() = %q, want empty", got)
+	}
+}
+
+func TestRegisterLevelDoesNotControlNamed(t *testing.T) {
+	if err := RegisterLevel("db", NewAtomicLevel(LogLevelError)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		UnregisterLevel("db")
+		SetNameLevels("")
+	})
+
+	l, err := NewLogger(LogConf{Level: int(LogLevelInfo)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := l.Named("db").Named("pool")
+	if !db.enabled(LogLevelInfo) {
+		t.Fatal("registered level applied to named logger")
+	}
+
+	if err := SetNameLevel("db", LogLevelError); err != nil {
+		t.Fatal(err)
+	}
+	if db.enabled(LogLevelInfo) {
+		t.Fatal("name level not applied to named logger")
+	}
+	if _, ok := lookupLevel("db"); !ok {
+		t.Fatal("lookupLevel(db) not found")
+	}
+}
@@ -1,13 +1,15 @@
 package logs
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
+	"time"
 )
 
 // findProjectRoot 查找项目的根目录（假设存在 go.mod 文件）
@@ -61,119 +63,269 @@ func GetLogPrefix(skip int) (logPrefix string) {
 	return fmt.Sprintf("%s %d: ", relativePath, line)
 }
 
-// 根据日志级别获取对应的log.Logger实例
-func getLoggerByLevel(logger *LogsLogger, level LogLevel) *log.Logger {
-	switch level {
-	case LogLevelDebug:
-		return logger.debugL
-	case LogLevelInfo:
-		return logger.infoL
-	case LogLevelWarn:
-		return logger.warnL
-	case LogLevelError:
-		return logger.errorL
-	case LogLevelFatal:
-		return logger.fatalL
-	case LogLevelPanic:
-		return logger.panicL
-	default:
-		return nil
+func containsFormatSpecifier(s string) bool {
+	return regexp.MustCompile(`%(?:\.\*|\*[0-9]*|[0-9.]*[a-zA-Z])`).MatchString(s)
+}
+
+func outputLog(logger *LogsLogger, level LogLevel, skip int, format string, v []interface{}, fields []Field) {
+	if logEnabled(logger, level, skip+1) {
+		writeLog(logger, level, skip+1, format, v, fields)
 	}
 }
 
-func containsFormatSpecifier(s string) bool {
-	return regexp.MustCompile(`%(?:\.\*|\*[0-9]*|[0-9.]*[a-zA-Z])`).MatchString(s)
+// outputLogCtx 与 outputLog 相同，级别检查通过后才从 ctx 中提取字段
+func outputLogCtx(ctx context.Context, logger *LogsLogger, level LogLevel, skip int, format string, v []interface{}) {
+	if logEnabled(logger, level, skip+1) {
+		writeLog(logger, level, skip+1, format, v, contextFields(ctx))
+	}
 }
 
-func outputLog(logger *LogsLogger, level LogLevel, skip int, format string, v ...interface{}) {
-	if level < LogLevel(logger.logConf.Level) {
-		return
+// logEnabled 调用者文件匹配 vmodule 规则时以规则的级别为准，否则以日志器的级别为准
+func logEnabled(logger *LogsLogger, level LogLevel, skip int) bool {
+	if vlevel, ok := vmoduleLevel(skip); ok {
+		return level >= vlevel
 	}
+	return logger.enabled(level)
+}
 
-	var msg string
+// writeLog 创建日志记录并输出
+func writeLog(logger *LogsLogger, level LogLevel, skip int, format string, v []interface{}, fields []Field) {
+	r := newRecord(logger, level, fields)
 	if format == "" {
-		msg = logger.encoder.Encode(v...)
+		r.Message = fmt.Sprint(v...)
+		r.Args = v
 	} else {
-		msg = logger.encoder.Encode(fmt.Sprintf(format, v...))
+		r.Message = fmt.Sprintf(format, v...)
+		r.Args = []interface{}{r.Message}
+	}
+	for _, a := range v {
+		if err, ok := a.(error); ok {
+			r.Err = err
+			break
+		}
+	}
+	if r.Err == nil {
+		r.Err = firstErrorField(r.Fields)
 	}
-	// else if containsFormatSpecifier(format) {
-	// // 如果包含格式化符号（如 %s、%d），则使用 fmt.Sprintf
-	// msg = logger.encoder.Encode(fmt.Sprintf(format, v...))
-	// }
 
-	if logger.hasRootFilePrefix {
-		msg = GetLogPrefix(skip) + msg
+	if r.Flags&(Lrootfile|Lshortfile|Llongfile) != 0 || logger.handler != nil {
+		r.Caller = callerAt(skip)
+	}
+	if r.out.stacktraceEnabled(level) {
+		r.Stack = captureStack(skip)
 	}
 
-	if logger.logWriteStrategy == LoggingSync || logger.logConf.Mode == LogModeConsole {
-		fmt.Println("刘伟开始叫了哦，快跑啊！")
-		internalLogger := getLoggerByLevel(logger, level)
-		internalLogger.Output(skip, msg)
-	} else {
-		fmt.Println("异步输出")
-		select {
-		case logChan <- logItem{logger: logger, level: level, msg: msg, skip: skip + 1}:
-		default:
-			log.Printf("日志通道已满，暂时无法异步写入日志: %s", msg)
+	emitRecord(logger, r)
+}
+
+// newRecord 创建一条日志记录，并填充日志器的前缀、标志和字段；记录的编码和输出使用同一份配置快照
+func newRecord(logger *LogsLogger, level LogLevel, fields []Field) *Record {
+	o := logger.outputs()
+	internalLogger := o.logger(level)
+
+	r := &Record{
+		Time:       time.Now(),
+		Level:      level,
+		Fields:     mergeFields(logger.fields, fields),
+		Prefix:     internalLogger.Prefix(),
+		Flags:      internalLogger.Flags(),
+		TimeLayout: o.timeLayout,
+		LoggerName: logger.Name(),
+		out:        o,
+	}
+	if o.location != nil {
+		r.Time = r.Time.In(o.location)
+	}
+	if o.rootFile {
+		r.Flags |= Lrootfile
+	}
+	return r
+}
+
+// emitRecord 编码并输出日志记录到日志器的各个输出目标；如果日志器以 slog.Handler 为后端，则交给 Handler 处理
+func emitRecord(logger *LogsLogger, r *Record) {
+	emitRecordPlain(logger, r, "")
+}
+
+// emitRecordPlain 与 emitRecord 相同，但 plainLine 不为空时，使用 plain 编码的输出目标直接写入 plainLine
+func emitRecordPlain(logger *LogsLogger, r *Record, plainLine string) {
+	if logger.handler != nil {
+		handleSlogRecord(logger.handler, r)
+		return
+	}
+
+	encode := func(encoder RecordEncoder) string {
+		if _, ok := encoder.(*PlainEncoder); ok && plainLine != "" {
+			return plainLine
 		}
+		return encoder.EncodeRecord(r)
+	}
+
+	o := r.out
+	if o == nil {
+		o = logger.outputs()
 	}
+	sinks := logger.sinkSet(o)
+	if sinks == nil {
+		emitLine(o, r.Level, encode(o.encoder))
+		return
+	}
+
+	var line string // 使用日志器编码器的输出目标共用同一次编码的结果
+	for _, s := range sinks.load() {
+		if !s.enabled(r.Level) {
+			continue
+		}
+		if s.encoder != nil {
+			s.emit(o, r.Level, encode(s.encoder))
+			continue
+		}
+		if line == "" {
+			line = encode(o.encoder)
+		}
+		s.emit(o, r.Level, line)
+	}
+}
+
+// emitLine 按快照中的写入策略将已编码的一行日志写入 Mode 对应的输出
+func emitLine(o *loggerOutput, level LogLevel, line string) {
+	q := o.queue
+	if o.strategy == LoggingSync || o.conf.Mode == LogModeConsole || q == nil {
+		writeLine(o, level, line)
+	} else {
+		q.push(logItem{out: o, level: level, line: line})
+	}
+}
+
+// emitUnfiltered 输出一条不受日志级别限制的日志，用于配置变化等审计信息
+func emitUnfiltered(logger *LogsLogger, level LogLevel, msg string, fields ...Field) {
+	r := newRecord(logger, level, fields)
+	r.Message = msg
+	r.Args = []interface{}{msg}
+	emitRecord(logger, r)
+}
+
+// writeLine 将编码后的一行日志写入快照中对应级别的输出
+func writeLine(o *loggerOutput, level LogLevel, line string) {
+	writeTo(o.logger(level).Writer(), line)
+}
+
+// writeTo 将一行日志写入 w
+func writeTo(w io.Writer, line string) {
+	if w == nil {
+		return
+	}
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+
+	// 与 log.Logger 一样串行写入，避免同步写入和异步 worker 同时写同一个 Writer
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	io.WriteString(w, line)
 }
 
 // output 方法的实现
 // Debug 输出 DEBUG 日志
 func Debug(v ...interface{}) {
-	outputLog(globalLogger, LogLevelDebug, 3, "", v...)
+	outputLog(globalLogger, LogLevelDebug, 3, "", v, nil)
 }
 
 func Debugf(format string, v ...interface{}) {
-	outputLog(globalLogger, LogLevelDebug, 3, format, v...)
+	outputLog(globalLogger, LogLevelDebug, 3, format, v, nil)
 }
 
 // Info 输出 INFO 日志
 func Info(v ...interface{}) {
-	outputLog(globalLogger, LogLevelInfo, 3, "", v...)
+	outputLog(globalLogger, LogLevelInfo, 3, "", v, nil)
 }
 
 func Infof(format string, v ...interface{}) {
-	outputLog(globalLogger, LogLevelInfo, 3, format, v...)
+	outputLog(globalLogger, LogLevelInfo, 3, format, v, nil)
 }
 
 // Warn 输出 WARN 日志
 func Warn(v ...interface{}) {
-	outputLog(globalLogger, LogLevelInfo, 3, "", v...)
+	outputLog(globalLogger, LogLevelWarn, 3, "", v, nil)
 }
 
 func Warnf(format string, v ...interface{}) {
-	outputLog(globalLogger, LogLevelInfo, 3, format, v...)
+	outputLog(globalLogger, LogLevelWarn, 3, format, v, nil)
 }
 
 // Error 输出 ERROR 日志
 func Error(v ...interface{}) {
-	outputLog(globalLogger, LogLevelError, 3, "", v...)
+	outputLog(globalLogger, LogLevelError, 3, "", v, nil)
 }
 
 func Errorf(format string, v ...interface{}) {
-	outputLog(globalLogger, LogLevelError, 3, format, v...)
+	outputLog(globalLogger, LogLevelError, 3, format, v, nil)
 }
 
 // Fatal 输出 FATAL 日志并退出程序
 func Fatal(v ...interface{}) {
-	outputLog(globalLogger, LogLevelFatal, 3, "", v...)
-	os.Exit(1)
+	outputLog(globalLogger, LogLevelFatal, 3, "", v, nil)
+	exitAfterFlush(1)
 }
 
 func Fatalf(format string, v ...interface{}) {
-	outputLog(globalLogger, LogLevelFatal, 3, format, v...)
-	os.Exit(1)
+	outputLog(globalLogger, LogLevelFatal, 3, format, v, nil)
+	exitAfterFlush(1)
 }
 
 // Panic 输出 PANIC 日志并触发 panic
 func Panic(v ...interface{}) {
-	outputLog(globalLogger, LogLevelPanic, 3, "", v...)
+	outputLog(globalLogger, LogLevelPanic, 3, "", v, nil)
+	flushQueues()
 	panic(fmt.Sprint(v...))
 }
 
 func Panicf(format string, v ...interface{}) {
-	outputLog(globalLogger, LogLevelPanic, 3, format, v...)
+	outputLog(globalLogger, LogLevelPanic, 3, format, v, nil)
+	flushQueues()
 	panic(fmt.Sprintf(format, v...))
 }
+
+// 结构化字段的输出方法 ---------------------------------------------------------------
+// With 返回附带结构化字段的全局子日志器
+func With(keysAndValues ...interface{}) *LogsLogger {
+	return globalLogger.With(keysAndValues...)
+}
+
+// WithFields 返回附带 fields 中所有字段的全局子日志器
+func WithFields(fields map[string]interface{}) *LogsLogger {
+	return globalLogger.WithFields(fields)
+}
+
+// Debugw 输出带结构化字段的 DEBUG 日志
+func Debugw(msg string, keysAndValues ...interface{}) {
+	outputLog(globalLogger, LogLevelDebug, 3, "", []interface{}{msg}, toFields(keysAndValues))
+}
+
+// Infow 输出带结构化字段的 INFO 日志
+func Infow(msg string, keysAndValues ...interface{}) {
+	outputLog(globalLogger, LogLevelInfo, 3, "", []interface{}{msg}, toFields(keysAndValues))
+}
+
+// Warnw 输出带结构化字段的 WARN 日志
+func Warnw(msg string, keysAndValues ...interface{}) {
+	outputLog(globalLogger, LogLevelWarn, 3, "", []interface{}{msg}, toFields(keysAndValues))
+}
+
+// Errorw 输出带结构化字段的 ERROR 日志
+func Errorw(msg string, keysAndValues ...interface{}) {
+	outputLog(globalLogger, LogLevelError, 3, "", []interface{}{msg}, toFields(keysAndValues))
+}
+
+// Fatalw 输出带结构化字段的 FATAL 日志并退出程序
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	outputLog(globalLogger, LogLevelFatal, 3, "", []interface{}{msg}, toFields(keysAndValues))
+	exitAfterFlush(1)
+}
+
+// Panicw 输出带结构化字段的 PANIC 日志并触发 panic
+func Panicw(msg string, keysAndValues ...interface{}) {
+	outputLog(globalLogger, LogLevelPanic, 3, "", []interface{}{msg}, toFields(keysAndValues))
+	flushQueues()
+	panic(msg)
+}
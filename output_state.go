@@ -0,0 +1,160 @@
+package logs
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// loggerOutput 写入时使用的日志器配置快照，发布后不再修改。
+// 配置变化时整体替换，每条日志只读取一次，热加载与写入并发时不会读到一半新一半旧的配置
+type loggerOutput struct {
+	debugL, infoL, warnL, errorL, fatalL, panicL *log.Logger
+
+	output     io.Writer        // Mode 对应的输出
+	conf       LogConf          // 发布时的日志配置
+	flags      int              // 移除 Lrootfile 后的标志
+	rootFile   bool             // 是否打印自定义的相对路径前缀
+	encoder    RecordEncoder    // 编码器
+	strategy   logWriteStrategy // 写入策略
+	timeLayout string           // 解析后的时间格式
+	location   *time.Location   // 日志时间所用的时区
+	queue      *asyncQueue      // 异步写入队列
+	hooks      *rotateHooks     // 日志文件切割后调用的钩子
+	sinks      *sinkSet         // 输出目标，为空时直接写入 Mode 对应的输出
+}
+
+// outputCell 保存日志器当前的输出快照。With、Named 创建的子日志器与父日志器共享同一个 outputCell，
+// 父日志器修改配置后子日志器立即使用新的输出
+type outputCell struct {
+	owner *LogsLogger // 发布快照的日志器
+	cur   atomic.Pointer[loggerOutput]
+}
+
+// snapshot 用日志器当前的配置创建快照；调用方需持有锁
+func (l *LogsLogger) snapshot() *loggerOutput {
+	return &loggerOutput{
+		debugL:     l.debugL,
+		infoL:      l.infoL,
+		warnL:      l.warnL,
+		errorL:     l.errorL,
+		fatalL:     l.fatalL,
+		panicL:     l.panicL,
+		output:     l.output,
+		conf:       l.logConf,
+		flags:      l.logFlags,
+		rootFile:   l.hasRootFilePrefix,
+		encoder:    l.encoder,
+		strategy:   l.logWriteStrategy,
+		timeLayout: resolveTimeLayout(l.logConf.TimeFormat),
+		location:   l.timeLocation,
+		queue:      l.queue,
+		hooks:      l.rotateHooks,
+		sinks:      l.sinks.Load(),
+	}
+}
+
+// publish 发布日志器当前的配置，之后的日志使用新的配置；调用方需持有锁
+func (l *LogsLogger) publish() {
+	c := l.out.Load()
+	if c == nil || c.owner != l {
+		c = &outputCell{owner: l}
+		l.out.Store(c)
+	}
+	c.cur.Store(l.snapshot())
+}
+
+// own 子日志器修改自己的配置前，从共享的快照复制一份配置，之后的修改不影响父日志器；调用方需持有锁
+func (l *LogsLogger) own() {
+	c := l.out.Load()
+	if c == nil || c.owner == l {
+		return
+	}
+	o := c.cur.Load()
+	l.debugL, l.infoL, l.warnL = o.debugL, o.infoL, o.warnL
+	l.errorL, l.fatalL, l.panicL = o.errorL, o.fatalL, o.panicL
+	l.output = o.output
+	l.logConf = o.conf
+	l.logFlags = o.flags
+	l.hasRootFilePrefix = o.rootFile
+	l.encoder = o.encoder
+	l.logWriteStrategy = o.strategy
+	l.timeLocation = o.location
+	l.queue = o.queue
+	l.rotateHooks = o.hooks
+	if l.sinks.Load() == nil {
+		l.sinks.Store(o.sinks)
+	}
+	l.publish()
+}
+
+// outputs 返回日志器当前的输出快照，没有发布过时使用日志器自身的配置
+func (l *LogsLogger) outputs() *loggerOutput {
+	if c := l.out.Load(); c != nil {
+		if o := c.cur.Load(); o != nil {
+			return o
+		}
+	}
+	return l.snapshot()
+}
+
+// sinkSet 返回日志器使用的输出目标：自己修改过输出目标时为自己的，否则与父日志器相同
+func (l *LogsLogger) sinkSet(o *loggerOutput) *sinkSet {
+	if ss := l.sinks.Load(); ss != nil {
+		return ss
+	}
+	return o.sinks
+}
+
+// retiredOutput 重新配置后不再使用的队列、文件和输出目标。
+// 调用方解锁后再排空旧队列，队列中的日志写入旧的输出后才释放文件，慢的输出不会让持有锁的其他调用等待
+type retiredOutput struct {
+	owner  *LogsLogger
+	queue  *asyncQueue
+	next   *asyncQueue // 替换旧队列的新队列，排空后继承旧队列的统计
+	handle *fileHandle
+	sinks  []*Sink // 对其中每个输出目标持有一个引用
+}
+
+// release 排空旧队列，然后释放旧的文件和输出目标；不能持有锁调用
+func (r *retiredOutput) release() {
+	if r == nil {
+		return
+	}
+	if r.queue != nil {
+		r.queue.release(r.owner, context.Background())
+		if r.next != nil && r.queue.owner == r.owner {
+			r.next.inherit(r.queue)
+		}
+	}
+	r.handle.release()
+	releaseSinks(r.sinks)
+}
+
+// logger 根据日志级别获取对应的 log.Logger 实例
+func (o *loggerOutput) logger(level LogLevel) *log.Logger {
+	switch level {
+	case LogLevelDebug:
+		return o.debugL
+	case LogLevelInfo:
+		return o.infoL
+	case LogLevelWarn:
+		return o.warnL
+	case LogLevelError:
+		return o.errorL
+	case LogLevelFatal:
+		return o.fatalL
+	case LogLevelPanic:
+		return o.panicL
+	default:
+		return nil
+	}
+}
+
+// stacktraceEnabled 该级别的日志是否需要附加调用栈，StacktraceLevel 为 0 时关闭
+func (o *loggerOutput) stacktraceEnabled(level LogLevel) bool {
+	sl := LogLevel(o.conf.StacktraceLevel)
+	return sl > LogLevelDebug && level >= sl
+}
@@ -0,0 +1,182 @@
+package logs
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record 一条完整的日志记录，由编码器负责将其渲染为一整行
+type Record struct {
+	Time       time.Time
+	Level      LogLevel
+	Message    string
+	Caller     Caller // 调用者信息（未开启 Lrootfile/Lshortfile/Llongfile 时为空）
+	LoggerName string
+	Fields     []Field
+	Err        error
+	Prefix     string        // 日志前缀，如 "[INFO] "
+	Flags      int           // 日志标志（Ldate、Ltime、Lrootfile 等）
+	TimeLayout string        // 时间布局（Go 布局或 epoch 预设），为空时由编码器决定
+	Args       []interface{} // 原始参数，供旧的 Encoder 使用
+	Stack      string        // 调用栈，每帧两行（函数名、"\t文件:行号"），未开启时为空
+
+	out *loggerOutput // 创建记录时的日志器配置快照，输出时使用同一份
+}
+
+// Caller 调用者的位置
+type Caller struct {
+	PC   uintptr
+	File string // 完整路径
+	Line int
+}
+
+// Defined 是否记录了调用者
+func (c Caller) Defined() bool {
+	return c.File != ""
+}
+
+// RelativePath 返回相对于项目根目录的路径，无法计算时返回完整路径
+func (c Caller) RelativePath() string {
+	relativePath, err := filepath.Rel(projectRoot, c.File)
+	if projectRoot == "" || err != nil || strings.HasPrefix(relativePath, "..") {
+		return c.File
+	}
+	return relativePath
+}
+
+// String 返回 "相对路径:行号"
+func (c Caller) String() string {
+	if !c.Defined() {
+		return ""
+	}
+	return c.RelativePath() + ":" + strconv.Itoa(c.Line)
+}
+
+// RecordEncoder 接收完整的日志记录并生成一整行日志（不含换行符）
+type RecordEncoder interface {
+	EncodeRecord(r *Record) string
+}
+
+// AdaptEncoder 将旧的 Encoder 适配为 RecordEncoder，消息以外的部分仍按 plain 格式输出
+func AdaptEncoder(e Encoder) RecordEncoder {
+	if re, ok := e.(RecordEncoder); ok {
+		return re
+	}
+	return &legacyEncoder{encoder: e}
+}
+
+type legacyEncoder struct {
+	encoder Encoder
+}
+
+func (e *legacyEncoder) EncodeRecord(r *Record) string {
+	var sb strings.Builder
+	appendPlainHeader(&sb, r)
+	sb.WriteString(encodeMessage(e.encoder, r.Fields, r.Args...))
+	appendPlainStack(&sb, r.Stack)
+	return sb.String()
+}
+
+// callerAt 获取调用者信息，skip 的含义与 GetLogPrefix 相同
+func callerAt(skip int) Caller {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		return Caller{}
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	return Caller{PC: pcs[0], File: frame.File, Line: frame.Line}
+}
+
+// levelTag 返回级别对应的默认前缀，如 "[INFO] "
+func levelTag(level LogLevel) string {
+	return "[" + strings.ToUpper(level.String()) + "] "
+}
+
+// String 返回级别的小写名称
+func (lv LogLevel) String() string {
+	switch lv {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	case LogLevelFatal:
+		return "fatal"
+	case LogLevelPanic:
+		return "panic"
+	default:
+		return "level(" + strconv.Itoa(int(lv)) + ")"
+	}
+}
+
+// ParseLogLevel 解析级别名称（不区分大小写），如 "debug"、"WARN"、"warning"，也接受数字 "0" 到 "5"
+func ParseLogLevel(name string) (LogLevel, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for lv := LogLevelDebug; lv <= LogLevelPanic; lv++ {
+		if name == lv.String() {
+			return lv, nil
+		}
+	}
+	if name == "warning" {
+		return LogLevelWarn, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil && LogLevel(n) >= LogLevelDebug && LogLevel(n) <= LogLevelPanic {
+		return LogLevel(n), nil
+	}
+	return 0, fmt.Errorf("unknown log level: %q", name)
+}
+
+// customPrefix 返回去掉默认级别前缀后的自定义前缀
+func (r *Record) customPrefix() string {
+	return strings.TrimSpace(strings.TrimPrefix(r.Prefix, levelTag(r.Level)))
+}
+
+// appendPlainHeader 按照标准库 log 的布局追加前缀、时间和调用者
+func appendPlainHeader(sb *strings.Builder, r *Record) {
+	if r.Flags&Lmsgprefix == 0 {
+		sb.WriteString(r.Prefix)
+	}
+
+	if r.TimeLayout != "" {
+		sb.WriteString(formatTime(recordTime(r), r.TimeLayout) + " ")
+	} else if r.Flags&(Ldate|Ltime|Lmicroseconds) != 0 {
+		t := recordTime(r)
+		if r.Flags&Ldate != 0 {
+			sb.WriteString(t.Format("2006/01/02 "))
+		}
+		if r.Flags&(Ltime|Lmicroseconds) != 0 {
+			if r.Flags&Lmicroseconds != 0 {
+				sb.WriteString(t.Format("15:04:05.000000 "))
+			} else {
+				sb.WriteString(t.Format("15:04:05 "))
+			}
+		}
+	}
+
+	if r.Caller.Defined() && r.Flags&Lrootfile == 0 && r.Flags&(Lshortfile|Llongfile) != 0 {
+		file := r.Caller.File
+		if r.Flags&Lshortfile != 0 {
+			file = filepath.Base(file)
+		}
+		sb.WriteString(file + ":" + strconv.Itoa(r.Caller.Line) + ": ")
+	}
+
+	if r.Flags&Lmsgprefix != 0 {
+		sb.WriteString(r.Prefix)
+	}
+
+	if r.Caller.Defined() && r.Flags&Lrootfile != 0 {
+		sb.WriteString(r.Caller.RelativePath() + " " + strconv.Itoa(r.Caller.Line) + ": ")
+	}
+
+	if r.LoggerName != "" {
+		sb.WriteString("[" + r.LoggerName + "] ")
+	}
+}
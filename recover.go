@@ -0,0 +1,123 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// flushTimeout 退出或重新 panic 前等待异步队列写完的最长时间
+const flushTimeout = 5 * time.Second
+
+// RecoverOption Recover 和 Go 的选项
+type RecoverOption func(*recoverOptions)
+
+type recoverOptions struct {
+	repanic bool
+	onPanic func(v interface{})
+}
+
+// Repanic 记录日志后重新 panic，让程序按原来的方式崩溃
+func Repanic() RecoverOption {
+	return func(o *recoverOptions) {
+		o.repanic = true
+	}
+}
+
+// OnPanic 记录日志后调用 fn，可用于上报监控或设置返回值
+func OnPanic(fn func(v interface{})) RecoverOption {
+	return func(o *recoverOptions) {
+		o.onPanic = fn
+	}
+}
+
+// Recover 在 defer 中使用，捕获 panic 并以 PANIC 级别记录 panic 的值和发生 panic 的 goroutine 的调用栈：
+//
+//	defer logger.Recover()
+//
+// 使用 Repanic 选项时，会先写完异步队列中的日志再重新 panic
+func (l *LogsLogger) Recover(opts ...RecoverOption) {
+	if v := recover(); v != nil {
+		handlePanic(l, v, opts)
+	}
+}
+
+// Go 启动一个 goroutine 执行 fn，fn 中的 panic 会被捕获并记录（见 Recover）
+func (l *LogsLogger) Go(fn func(), opts ...RecoverOption) {
+	go func() {
+		defer l.Recover(opts...)
+		fn()
+	}()
+}
+
+// Recover 使用全局日志器记录 panic，需要在 defer 中直接调用：defer logs.Recover()
+func Recover(opts ...RecoverOption) {
+	if v := recover(); v != nil {
+		handlePanic(globalLogger, v, opts)
+	}
+}
+
+// Go 启动一个 goroutine 执行 fn，panic 由全局日志器记录
+func Go(fn func(), opts ...RecoverOption) {
+	go func() {
+		defer Recover(opts...)
+		fn()
+	}()
+}
+
+// handlePanic 记录捕获的 panic，panic 发生的位置作为日志的调用者
+func handlePanic(l *LogsLogger, v interface{}, opts []RecoverOption) {
+	var o recoverOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var fields []Field
+	if err, ok := v.(error); ok {
+		fields = append(fields, F("panic", err))
+	} else {
+		fields = append(fields, F("panic", fmt.Sprint(v)))
+	}
+
+	r := newRecord(l, LogLevelPanic, fields)
+	r.Message = fmt.Sprintf("panic recovered: %v", v)
+	r.Args = []interface{}{r.Message}
+	r.Err = firstErrorField(fields)
+	r.Stack, r.Caller = stackAndCaller(3)
+	if r.Flags&(Lrootfile|Lshortfile|Llongfile) == 0 && l.handler == nil {
+		r.Caller = Caller{}
+	}
+	emitRecord(l, r)
+
+	if o.onPanic != nil {
+		o.onPanic(v)
+	}
+	if o.repanic {
+		flushQueues()
+		panic(v)
+	}
+}
+
+// flushQueues 等待所有异步队列中已有的日志写入完成，最多等待 flushTimeout
+func flushQueues() {
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	queuesMu.Lock()
+	all := make([]*asyncQueue, 0, len(queues))
+	for q := range queues {
+		all = append(all, q)
+	}
+	queuesMu.Unlock()
+
+	for _, q := range all {
+		q.flush(ctx)
+	}
+}
+
+// exitAfterFlush 写完异步队列中的日志后退出程序
+func exitAfterFlush(code int) {
+	flushQueues()
+	os.Exit(code)
+}
@@ -0,0 +1,76 @@
+package logs
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecover(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		value   interface{}
+		async   bool
+		repanic bool
+		want    []string
+	}{
+		{"string", "boom", false, false, []string{`"level":"panic"`, `"message":"panic recovered: boom"`, `"panic":"boom"`, `"stacktrace":"`}},
+		{"error", io.EOF, false, false, []string{`"panic":"EOF"`, `"error_type":"*errors.errorString"`}},
+		{"repanic", "again", false, true, []string{`"message":"panic recovered: again"`}},
+		{"repanic flushes async queue", "async", true, true, []string{`"message":"panic recovered: async"`}},
+	} {
+		l, buf := newBufferLogger(t)
+		if err := l.SetEncoding(LogEncodingJSON); err != nil {
+			t.Fatal(err)
+		}
+		if tc.async {
+			l.SetLogWriteStrategy(LoggingAsync)
+			t.Cleanup(func() { l.Close(context.Background()) })
+		}
+		var hooked interface{}
+		opts := []RecoverOption{OnPanic(func(v interface{}) { hooked = v })}
+		if tc.repanic {
+			opts = append(opts, Repanic())
+		}
+
+		var repanicked interface{}
+		func() {
+			defer func() { repanicked = recover() }()
+			defer l.Recover(opts...)
+			panic(tc.value)
+		}()
+
+		if hooked != tc.value {
+			t.Errorf("%s: OnPanic got %v", tc.name, hooked)
+		}
+		if tc.repanic != (repanicked == tc.value) {
+			t.Errorf("%s: re-panicked with %v, want repanic=%v", tc.name, repanicked, tc.repanic)
+		}
+		got := buf.String() // 重新 panic 之前已经写完异步队列
+		for _, want := range tc.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: log %q does not contain %s", tc.name, got, want)
+			}
+		}
+	}
+}
+
+func TestGoRecovers(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	done := make(chan interface{}, 1)
+	l.Go(func() { panic("in goroutine") }, OnPanic(func(v interface{}) { done <- v }))
+
+	select {
+	case v := <-done:
+		if v != "in goroutine" {
+			t.Fatalf("OnPanic got %v", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("panic in goroutine not recovered")
+	}
+	if got := buf.String(); !strings.Contains(got, "[PANIC]") || !strings.Contains(got, "panic recovered: in goroutine") {
+		t.Fatalf("log = %q", got)
+	}
+}
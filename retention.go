@@ -0,0 +1,257 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultRetentionInterval 清理的默认间隔
+const defaultRetentionInterval = time.Minute
+
+// RetentionConf 目录级别的日志清理配置。MaxBackups、KeepDays 只作用于单个日志文件，
+// 日志器多、按级别分流时整个目录仍可能占满磁盘，这里按整个目录（包括子目录）的总量清理
+type RetentionConf struct {
+	Dir          string        // 日志目录
+	MaxTotalSize int           // 目录中日志文件的总大小上限（MB），正在写入的文件也计入，0 表示不限制
+	MaxFiles     int           // 切割出的文件数量上限，0 表示不限制
+	KeepDays     int           // 切割出的文件保留天数，0 表示不限制
+	Patterns     []string      // 参与清理的文件名（filepath.Match 模式），默认 *.log、*.log.gz
+	Interval     time.Duration // 清理间隔，默认 1 分钟
+}
+
+// RetentionManager 按 RetentionConf 定期清理日志目录，从最旧的切割文件开始删除，
+// 不会删除任何日志器正在写入的文件，以及正在压缩或刚切割出的文件。删除的文件通过 Diagnostics 通道报告
+type RetentionManager struct {
+	conf RetentionConf
+	dir  string // 绝对路径
+
+	runMu sync.Mutex      // 串行化清理，保护 seen
+	seen  map[string]bool // 上一次清理时扫描到的文件，为 nil 表示还没有扫描过
+	mu    sync.Mutex      // 保护 stop
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewRetentionManager 创建目录清理器，调用 Start 后开始定期清理
+func NewRetentionManager(conf RetentionConf) (*RetentionManager, error) {
+	if conf.Dir == "" {
+		return nil, &ConfError{Field: "dir", Err: errors.New("retention dir is required")}
+	}
+	if conf.MaxTotalSize < 0 {
+		return nil, &ConfError{Field: "max_total_size", Err: fmt.Errorf("must not be negative: %d", conf.MaxTotalSize)}
+	}
+	if conf.MaxFiles < 0 {
+		return nil, &ConfError{Field: "max_files", Err: fmt.Errorf("must not be negative: %d", conf.MaxFiles)}
+	}
+	if conf.KeepDays < 0 {
+		return nil, &ConfError{Field: "keep_days", Err: fmt.Errorf("must not be negative: %d", conf.KeepDays)}
+	}
+	if len(conf.Patterns) == 0 {
+		conf.Patterns = []string{"*.log", "*.log.gz"}
+	}
+	for _, p := range conf.Patterns {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return nil, &ConfError{Field: "patterns", Err: fmt.Errorf("%q: %v", p, err)}
+		}
+	}
+	if conf.Interval <= 0 {
+		conf.Interval = defaultRetentionInterval
+	}
+
+	dir, err := filepath.Abs(conf.Dir)
+	if err != nil {
+		return nil, err
+	}
+	return &RetentionManager{conf: conf, dir: dir}, nil
+}
+
+// Start 立即清理一次，之后按 Interval 定期清理；已经启动时不做任何事
+func (m *RetentionManager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		return
+	}
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(m.conf.Interval)
+		defer ticker.Stop()
+		for {
+			m.Run()
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}(m.stop, m.done)
+}
+
+// Stop 停止定期清理并等待正在进行的清理结束，可以多次调用
+func (m *RetentionManager) Stop() {
+	m.mu.Lock()
+	stop, done := m.stop, m.done
+	m.stop, m.done = nil, nil
+	m.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// retentionFile 参与清理的文件
+type retentionFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+	active  bool // 正在写入，不计入切割出的文件
+	busy    bool // 正在压缩或刚切割出，计入数量但这一轮不删除
+}
+
+// Run 清理一次，返回删除的文件。依次按 KeepDays、MaxFiles、MaxTotalSize 从最旧的切割文件开始删除
+func (m *RetentionManager) Run() ([]string, error) {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+
+	files, err := m.scan()
+	if err != nil {
+		reportDiagnostic(Diagnostic{Source: "retention", Message: "scan log dir failed", Path: m.dir, Err: err})
+		return nil, err
+	}
+	// 从旧到新
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	var total int64
+	rotated := 0
+	for _, f := range files {
+		total += f.size
+		if !f.active {
+			rotated++
+		}
+	}
+
+	var removed []string
+	var errs []error
+	cutoff := time.Now().Add(-time.Duration(m.conf.KeepDays) * 24 * time.Hour)
+	maxTotal := int64(m.conf.MaxTotalSize) * 1024 * 1024
+	for _, f := range files {
+		if f.active || f.busy {
+			continue
+		}
+		var reason string
+		switch {
+		case m.conf.KeepDays > 0 && f.modTime.Before(cutoff):
+			reason = fmt.Sprintf("older than %d days", m.conf.KeepDays)
+		case m.conf.MaxFiles > 0 && rotated > m.conf.MaxFiles:
+			reason = fmt.Sprintf("more than %d rotated files", m.conf.MaxFiles)
+		case maxTotal > 0 && total > maxTotal:
+			reason = fmt.Sprintf("total size exceeds %d MB", m.conf.MaxTotalSize)
+		default:
+			continue
+		}
+
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+			reportDiagnostic(Diagnostic{Source: "retention", Message: "remove failed: " + reason, Path: f.path, Size: f.size, Err: err})
+			continue
+		}
+		total -= f.size
+		rotated--
+		removed = append(removed, f.path)
+		reportDiagnostic(Diagnostic{Source: "retention", Message: "removed: " + reason, Path: f.path, Size: f.size})
+	}
+	return removed, errors.Join(errs...)
+}
+
+// scan 列出目录（包括子目录）中匹配的普通文件，标记正在写入或正在压缩的文件
+func (m *RetentionManager) scan() ([]retentionFile, error) {
+	active := activeFiles()
+	now := time.Now()
+	seen := make(map[string]bool)
+	var files []retentionFile
+	err := filepath.WalkDir(m.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == m.dir {
+				return err
+			}
+			return nil // 子目录在遍历过程中被删除等
+		}
+		if d.IsDir() || !d.Type().IsRegular() || !m.match(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		seen[path] = true
+		files = append(files, retentionFile{
+			path:    path,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+			active:  active[path],
+			busy:    compressing(path) || m.awaitingCompression(path, info.ModTime(), now),
+		})
+		return nil
+	})
+	if err == nil {
+		m.seen = seen
+	}
+	return files, err
+}
+
+func (m *RetentionManager) match(name string) bool {
+	for _, p := range m.conf.Patterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// compressing .gz 文件与原文件同时存在时，说明正在压缩，两者都不能删除
+func compressing(path string) bool {
+	if filepath.Ext(path) == ".gz" {
+		return fileExists(path[:len(path)-len(".gz")])
+	}
+	return fileExists(path + ".gz")
+}
+
+// awaitingCompression 刚切割出的未压缩文件可能即将被压缩：上一次清理之后才出现，
+// 或者修改时间在压缩等待时间之内，这一轮不删除。调用方需持有 runMu
+func (m *RetentionManager) awaitingCompression(path string, modTime, now time.Time) bool {
+	if filepath.Ext(path) == ".gz" {
+		return false
+	}
+	if m.seen != nil && !m.seen[path] {
+		return true
+	}
+	return now.Sub(modTime) < compressWindow
+}
+
+// activeFiles 返回所有日志器正在写入的文件（绝对路径）：每个共享写入器的路径，
+// 按时间切割时还包括当前文件
+func activeFiles() map[string]bool {
+	fileWritersMu.Lock()
+	defer fileWritersMu.Unlock()
+
+	active := make(map[string]bool, len(fileWriters))
+	for path, w := range fileWriters {
+		active[path] = true
+		if r, ok := w.out.(*timeRotator); ok {
+			if p := r.current.Load(); p != nil {
+				active[*p] = true
+			}
+		}
+	}
+	return active
+}
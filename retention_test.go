@@ -0,0 +1,148 @@
+package logs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// writeOldFile 创建修改时间在 age 之前的文件
+func writeOldFile(t *testing.T, path string, size int, age time.Duration) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-age)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestRetention(t *testing.T, conf RetentionConf) *RetentionManager {
+	t.Helper()
+	m, err := NewRetentionManager(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func runRetention(t *testing.T, m *RetentionManager) []string {
+	t.Helper()
+	removed, err := m.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range removed {
+		removed[i] = filepath.Base(p)
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+func TestRetentionMaxFilesAndKeepDays(t *testing.T) {
+	dir := t.TempDir()
+	writeOldFile(t, filepath.Join(dir, "app-1.log.gz"), 10, 72*time.Hour)
+	writeOldFile(t, filepath.Join(dir, "app-2.log.gz"), 10, 3*time.Hour)
+	writeOldFile(t, filepath.Join(dir, "app-3.log.gz"), 10, 2*time.Hour)
+	writeOldFile(t, filepath.Join(dir, "archive", "app-4.log.gz"), 10, time.Hour)
+	writeOldFile(t, filepath.Join(dir, "notes.txt"), 10, 96*time.Hour) // 不匹配
+
+	drainDiagnostics()
+	m := newTestRetention(t, RetentionConf{Dir: dir, MaxFiles: 2, KeepDays: 2})
+	// app-1 超过保留天数，app-2 超过文件数量
+	if got := runRetention(t, m); len(got) != 2 || got[0] != "app-1.log.gz" || got[1] != "app-2.log.gz" {
+		t.Fatalf("removed = %q", got)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case d := <-Diagnostics():
+			if d.Source != "retention" || d.Err != nil || d.Size != 10 {
+				t.Fatalf("diagnostic = %+v", d)
+			}
+		default:
+			t.Fatal("removal not reported")
+		}
+	}
+	if !fileExists(filepath.Join(dir, "notes.txt")) || !fileExists(filepath.Join(dir, "archive", "app-4.log.gz")) {
+		t.Fatal("removed unmatched or newer files")
+	}
+}
+
+func TestRetentionKeepsActiveFiles(t *testing.T) {
+	dir := t.TempDir()
+	newPath := filepath.Join(dir, "new.log")
+	parent, err := NewLogger(LogConf{Mode: LogModeFile, Path: filepath.Join(dir, "old.log")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parent.Close(context.Background())
+	child := parent.With("child", true)
+	defer child.Close(context.Background())
+	if err := parent.SetUp(LogConf{Mode: LogModeFile, Path: newPath}); err != nil {
+		t.Fatal(err)
+	}
+	child.Info("follows the parent to new.log")
+	mtime := time.Now().Add(-time.Hour) // 不因刚写入而被当作刚切割出的文件
+	if err := os.Chtimes(newPath, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	writeOldFile(t, filepath.Join(dir, "app-1.log.gz"), 1024*1024, time.Hour)
+
+	m := newTestRetention(t, RetentionConf{Dir: dir, MaxTotalSize: 1})
+	if got := runRetention(t, m); len(got) != 1 || got[0] != "app-1.log.gz" {
+		t.Fatalf("removed = %q", got)
+	}
+	if !fileExists(newPath) {
+		t.Fatal("removed the file a child logger is writing")
+	}
+}
+
+func TestRetentionKeepsFilesBeingCompressed(t *testing.T) {
+	dir := t.TempDir()
+	m := newTestRetention(t, RetentionConf{Dir: dir, MaxFiles: 1})
+	runRetention(t, m) // 第一次扫描，之后出现的文件视为刚切割出
+
+	// 压缩中：原文件和 .gz 同时存在
+	writeOldFile(t, filepath.Join(dir, "app-1.log"), 10, time.Hour)
+	writeOldFile(t, filepath.Join(dir, "app-1.log.gz"), 10, time.Hour)
+	// 刚切割出，还没有开始压缩
+	writeOldFile(t, filepath.Join(dir, "app-2.log"), 10, time.Hour)
+	writeOldFile(t, filepath.Join(dir, "app-3.log.gz"), 10, 2*time.Hour)
+
+	// app-2 在上一次清理之后出现，这一轮保留；只能删除 app-3
+	if got := runRetention(t, m); len(got) != 1 || got[0] != "app-3.log.gz" {
+		t.Fatalf("removed = %q", got)
+	}
+	// 下一轮 app-2 不再视为刚切割出，而 app-1 仍在压缩
+	if got := runRetention(t, m); len(got) != 1 || got[0] != "app-2.log" {
+		t.Fatalf("removed = %q", got)
+	}
+
+	// 压缩完成（原文件被删除）后 .gz 可以删除
+	if err := os.Remove(filepath.Join(dir, "app-1.log")); err != nil {
+		t.Fatal(err)
+	}
+	writeOldFile(t, filepath.Join(dir, "app-5.log.gz"), 10, time.Minute)
+	if got := runRetention(t, m); len(got) != 1 || got[0] != "app-1.log.gz" {
+		t.Fatalf("removed = %q", got)
+	}
+}
+
+func TestRetentionKeepsRecentUncompressed(t *testing.T) {
+	dir := t.TempDir()
+	writeOldFile(t, filepath.Join(dir, "app-1.log"), 10, 2*time.Hour)
+	writeOldFile(t, filepath.Join(dir, "app-2.log"), 2*1024*1024, time.Second) // 修改时间在压缩等待时间之内
+
+	// 总大小仍然超出上限，但 app-2 可能即将被压缩，不删除
+	m := newTestRetention(t, RetentionConf{Dir: dir, MaxTotalSize: 1})
+	if got := runRetention(t, m); len(got) != 1 || got[0] != "app-1.log" {
+		t.Fatalf("removed = %q", got)
+	}
+}
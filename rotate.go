@@ -0,0 +1,483 @@
+package logs
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 日志文件的切割策略
+const (
+	RotateSize       = "size"        // 按大小切割（MaxSize），默认
+	RotateHourly     = "hourly"      // 每小时一个文件
+	RotateDaily      = "daily"       // 每天一个文件
+	RotateHourlySize = "hourly+size" // 每小时一个文件，超过 MaxSize 时在同一小时内继续切割
+	RotateDailySize  = "daily+size"  // 每天一个文件，超过 MaxSize 时在同一天内继续切割
+)
+
+// defaultRotateMaxSize MaxSize 为 0 时的大小上限（MB），与 lumberjack 一致
+const defaultRotateMaxSize = 100
+
+// parseRotation 解析切割策略，返回时间周期（按大小切割时为空）以及是否同时按大小切割
+func parseRotation(rotation string) (period string, bySize bool, err error) {
+	switch strings.ToLower(strings.TrimSpace(rotation)) {
+	case "", RotateSize:
+		return "", true, nil
+	case RotateHourly:
+		return RotateHourly, false, nil
+	case RotateDaily:
+		return RotateDaily, false, nil
+	case RotateHourlySize, "size+hourly":
+		return RotateHourly, true, nil
+	case RotateDailySize, "size+daily":
+		return RotateDaily, true, nil
+	default:
+		return "", false, fmt.Errorf("unsupported rotation: %q", rotation)
+	}
+}
+
+// validateRotation 校验切割策略和文件名模式，返回出错的配置项；path 用于生成默认的文件名模式
+func validateRotation(rotation, pattern, path string) (field string, err error) {
+	period, _, err := parseRotation(rotation)
+	if err != nil {
+		return "rotation", err
+	}
+	if period == "" {
+		if pattern != "" {
+			return "file_pattern", errors.New("file pattern requires hourly or daily rotation")
+		}
+		return "", nil
+	}
+	if pattern == "" {
+		pattern = defaultFilePattern(path, period)
+	}
+	if err := validateFilePattern(pattern, period); err != nil {
+		return "file_pattern", err
+	}
+	return "", nil
+}
+
+// defaultFilePattern 按日志文件路径生成文件名模式：app.log -> app-%Y%m%d.log（每小时切割时为 app-%Y%m%d-%H.log）
+func defaultFilePattern(path string, period string) string {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	layout := "-%Y%m%d"
+	if period == RotateHourly {
+		layout = "-%Y%m%d-%H"
+	}
+	return strings.TrimSuffix(base, ext) + layout + ext
+}
+
+// validateFilePattern 检查文件名模式：只支持 %Y %m %d %H %M %j %%，目录部分不能包含时间，
+// 且模式的时间精度要能区分每个切割周期
+func validateFilePattern(pattern string, period string) error {
+	if strings.Contains(filepath.Dir(pattern), "%") {
+		return fmt.Errorf("file pattern %q: time verbs are only allowed in the file name", pattern)
+	}
+
+	verbs := make(map[byte]bool)
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' {
+			continue
+		}
+		if i+1 == len(pattern) {
+			return fmt.Errorf("file pattern %q: trailing %%", pattern)
+		}
+		i++
+		switch c := pattern[i]; c {
+		case 'Y', 'm', 'd', 'H', 'M', 'j', '%':
+			verbs[c] = true
+		default:
+			return fmt.Errorf("file pattern %q: unsupported verb %%%c", pattern, c)
+		}
+	}
+
+	daily := verbs['Y'] && (verbs['j'] || verbs['m'] && verbs['d'])
+	if !daily {
+		return fmt.Errorf("file pattern %q must contain the date (%%Y%%m%%d or %%Y%%j)", pattern)
+	}
+	if period == RotateHourly && !verbs['H'] {
+		return fmt.Errorf("file pattern %q must contain the hour (%%H) for hourly rotation", pattern)
+	}
+	return nil
+}
+
+// formatFilePattern 用时间 t 替换模式中的 %Y 等占位符
+func formatFilePattern(pattern string, t time.Time) string {
+	var sb strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i+1 == len(pattern) {
+			sb.WriteByte(pattern[i])
+			continue
+		}
+		i++
+		switch pattern[i] {
+		case 'Y':
+			sb.WriteString(fmt.Sprintf("%04d", t.Year()))
+		case 'm':
+			sb.WriteString(fmt.Sprintf("%02d", int(t.Month())))
+		case 'd':
+			sb.WriteString(fmt.Sprintf("%02d", t.Day()))
+		case 'H':
+			sb.WriteString(fmt.Sprintf("%02d", t.Hour()))
+		case 'M':
+			sb.WriteString(fmt.Sprintf("%02d", t.Minute()))
+		case 'j':
+			sb.WriteString(fmt.Sprintf("%03d", t.YearDay()))
+		default:
+			sb.WriteByte(pattern[i])
+		}
+	}
+	return sb.String()
+}
+
+// filePatternRegexp 匹配由模式生成的文件名（只比较文件名），包括同一周期内按大小切割的 .1、.2 以及压缩后的 .gz
+func filePatternRegexp(stem, ext string) *regexp.Regexp {
+	toRe := func(p string) string {
+		var sb strings.Builder
+		for i := 0; i < len(p); i++ {
+			if p[i] != '%' || i+1 == len(p) {
+				sb.WriteString(regexp.QuoteMeta(string(p[i])))
+				continue
+			}
+			i++
+			switch p[i] {
+			case 'Y':
+				sb.WriteString(`\d{4}`)
+			case 'j':
+				sb.WriteString(`\d{3}`)
+			case 'm', 'd', 'H', 'M':
+				sb.WriteString(`\d{2}`)
+			default:
+				sb.WriteString(regexp.QuoteMeta(string(p[i])))
+			}
+		}
+		return sb.String()
+	}
+	return regexp.MustCompile(`^` + toRe(stem) + `(\.\d+)?` + toRe(ext) + `(\.gz)?$`)
+}
+
+// timeRotator 按时间（可同时按大小）切割的日志文件，link 指向当前写入的文件。
+// Write 和 Close 由调用方持有 writeMu 串行调用，清理旧文件在后台进行
+type timeRotator struct {
+	link       string // 指向当前文件的符号链接，即配置中的日志文件路径
+	dir        string // 日志文件所在目录
+	stem, ext  string // 文件名模式，同一周期内按大小切割时在两者之间插入 .1、.2
+	period     string
+	maxSize    int64 // 为 0 时不按大小切割
+	maxBackups int
+	keepDays   int
+	compress   bool
+	loc        *time.Location
+	match      *regexp.Regexp
+
+	file      *os.File
+	size      int64
+	seq       int
+	periodEnd time.Time
+	current   atomic.Pointer[string] // 当前文件路径，清理时跳过
+
+	millMu   sync.Mutex // 串行化后台清理
+	onRotate rotateFunc // 切割时调用，可以为空
+}
+
+func newTimeRotator(path string, rot rotationConf) *timeRotator {
+	period, bySize, _ := parseRotation(rot.rotation)
+	pattern := rot.filePattern
+	if pattern == "" {
+		pattern = defaultFilePattern(path, period)
+	}
+
+	// 相对路径的模式相对于日志文件所在目录
+	dir := filepath.Dir(pattern)
+	if !filepath.IsAbs(pattern) {
+		dir = filepath.Join(filepath.Dir(path), dir)
+	}
+	base := filepath.Base(pattern)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	r := &timeRotator{
+		link:       path,
+		dir:        dir,
+		stem:       stem,
+		ext:        ext,
+		period:     period,
+		maxBackups: rot.maxBackups,
+		keepDays:   rot.keepDays,
+		compress:   rot.compress,
+		loc:        rot.location,
+		match:      filePatternRegexp(stem, ext),
+	}
+	if bySize {
+		maxSize := rot.maxSize
+		if maxSize <= 0 {
+			maxSize = defaultRotateMaxSize
+		}
+		r.maxSize = int64(maxSize) * 1024 * 1024
+	}
+	if r.loc == nil {
+		r.loc = time.Local
+	}
+	return r
+}
+
+// periodBounds 返回 t 所在切割周期的开始和结束时间。
+// 夏令时结束时重复的一小时按实际时间划分，不用 time.Date 以免得到第一次出现的时刻
+func (r *timeRotator) periodBounds(t time.Time) (start, end time.Time) {
+	if r.period == RotateHourly {
+		start = t.Add(-time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+		return start, start.Add(time.Hour)
+	}
+	next := time.Date(t.Year(), t.Month(), t.Day()+1, 12, 0, 0, 0, t.Location())
+	return dayStart(t), dayStart(next)
+}
+
+// dayStart 返回 t 所在日期的开始时间；零点因夏令时不存在时为切换后的第一个时刻
+func dayStart(t time.Time) time.Time {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if start.Day() != t.Day() {
+		_, start = start.ZoneBounds()
+	}
+	return start
+}
+
+// fileName 周期开始时间为 start、序号为 seq 的文件路径，序号 0 不带后缀
+func (r *timeRotator) fileName(start time.Time, seq int) string {
+	name := formatFilePattern(r.stem, start)
+	if seq > 0 {
+		name += "." + strconv.Itoa(seq)
+	}
+	return filepath.Join(r.dir, name+formatFilePattern(r.ext, start))
+}
+
+func (r *timeRotator) Write(p []byte) (int, error) {
+	now := time.Now().In(r.loc)
+	switch {
+	case r.file == nil:
+		if err := r.open(now, false, ""); err != nil {
+			return 0, err
+		}
+	case !now.Before(r.periodEnd):
+		if err := r.rotate(now, false); err != nil {
+			return 0, err
+		}
+	case r.maxSize > 0 && r.size+int64(len(p)) > r.maxSize && r.size > 0:
+		if err := r.rotate(now, true); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate 关闭当前文件并打开下一个文件，nextSeq 为 true 时在同一周期内递增序号
+func (r *timeRotator) rotate(now time.Time, nextSeq bool) error {
+	closed := r.file.Name()
+	if err := r.closeFile(); err != nil {
+		return err
+	}
+	return r.open(now, nextSeq, closed)
+}
+
+// open 打开 now 所在周期的文件：追加到该周期最新的文件，超过大小时（或 nextSeq 为 true）使用下一个序号。
+// closed 为刚切割出的文件，为空表示不是切割
+func (r *timeRotator) open(now time.Time, nextSeq bool, closed string) error {
+	start, end := r.periodBounds(now)
+	r.moveRegularLink()
+
+	seq := 0
+	if nextSeq {
+		seq = r.seq + 1
+	} else {
+		for rotatedExists(r.fileName(start, seq+1)) {
+			seq++
+		}
+		if info, err := os.Stat(r.fileName(start, seq)); err != nil {
+			if fileExists(r.fileName(start, seq) + ".gz") {
+				seq++ // 已经压缩，不再追加
+			}
+		} else if r.maxSize > 0 && info.Size() >= r.maxSize {
+			seq++
+		}
+	}
+
+	name := r.fileName(start, seq)
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return fmt.Errorf("can't make directories for new logfile: %s", err)
+	}
+	// 先记录当前文件再创建，保证后台清理不会处理刚创建的文件
+	r.current.Store(&name)
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("can't open new logfile: %s", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	r.file, r.size, r.seq, r.periodEnd = f, info.Size(), seq, end
+	r.updateLink(name)
+	done := make(chan struct{})
+	go r.mill(done)
+	if closed != "" && r.onRotate != nil {
+		r.onRotate(closed, func() (string, bool) {
+			<-done
+			return finalRotatedPath(closed)
+		})
+	}
+	return nil
+}
+
+// moveRegularLink link 是普通文件（如之前按大小切割时的日志文件）时，按修改时间把它移到对应周期的文件中
+func (r *timeRotator) moveRegularLink() {
+	info, err := os.Lstat(r.link)
+	if err != nil || !info.Mode().IsRegular() {
+		return
+	}
+	modTime := info.ModTime().In(r.loc)
+	for seq := 0; ; seq++ {
+		if name := r.fileName(modTime, seq); !rotatedExists(name) {
+			if os.MkdirAll(filepath.Dir(name), 0755) == nil {
+				os.Rename(r.link, name)
+			}
+			return
+		}
+	}
+}
+
+// updateLink 让 link 指向当前文件 name，使用相对路径以便整个目录可以移动
+func (r *timeRotator) updateLink(name string) {
+	if r.link == name {
+		return
+	}
+	target, err := filepath.Rel(filepath.Dir(r.link), name)
+	if err != nil {
+		target = name
+	}
+	tmp := r.link + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return // 不支持符号链接的系统上不创建
+	}
+	if err := os.Rename(tmp, r.link); err != nil {
+		os.Remove(tmp)
+	}
+}
+
+func fileExists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
+
+// rotatedExists 切割后的文件是否存在，包括已经压缩的
+func rotatedExists(name string) bool {
+	return fileExists(name) || fileExists(name+".gz")
+}
+
+func (r *timeRotator) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// Close 关闭当前文件，之后的写入会重新打开
+func (r *timeRotator) Close() error {
+	return r.closeFile()
+}
+
+// mill 按 KeepDays、MaxBackups 删除旧文件，并在需要时压缩，跳过当前写入的文件；完成后关闭 done
+func (r *timeRotator) mill(done chan struct{}) {
+	defer close(done)
+	r.millMu.Lock()
+	defer r.millMu.Unlock()
+
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return
+	}
+	var current string
+	if p := r.current.Load(); p != nil {
+		current = filepath.Base(*p)
+	}
+
+	type backup struct {
+		name    string
+		modTime time.Time
+	}
+	var backups []backup
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == current || !r.match.MatchString(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		backups = append(backups, backup{name: e.Name(), modTime: info.ModTime()})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].modTime.After(backups[j].modTime) })
+
+	var keep []backup
+	cutoff := time.Now().Add(-time.Duration(r.keepDays) * 24 * time.Hour)
+	for i, b := range backups {
+		if (r.maxBackups > 0 && i >= r.maxBackups) || (r.keepDays > 0 && b.modTime.Before(cutoff)) {
+			os.Remove(filepath.Join(r.dir, b.name))
+			continue
+		}
+		keep = append(keep, b)
+	}
+
+	if r.compress {
+		for _, b := range keep {
+			if !strings.HasSuffix(b.name, ".gz") {
+				gzipFile(filepath.Join(r.dir, b.name))
+			}
+		}
+	}
+}
+
+// gzipFile 将 name 压缩为 name.gz 并删除原文件
+func gzipFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	err = errors.Join(err, gz.Close(), dst.Close())
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+	os.Chtimes(name+".gz", info.ModTime(), info.ModTime())
+	return os.Remove(name)
+}
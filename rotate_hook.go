@@ -0,0 +1,400 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// RotateHook 日志文件切割后在后台调用，closedPath 为切割出的文件（启用压缩时为压缩后的 .gz 文件）。
+// 返回错误时会重试
+type RotateHook func(closedPath string) error
+
+// 切割钩子的最大尝试次数，以及第一次重试前的等待时间（之后每次翻倍）
+var (
+	rotateHookAttempts = 3
+	rotateHookBackoff  = time.Second
+)
+
+// rotateHooks 日志器注册的切割钩子，修改时整体替换。With、Named 创建的子日志器与父日志器共享
+type rotateHooks struct {
+	mu   sync.Mutex // 串行化修改
+	list atomic.Pointer[[]RotateHook]
+}
+
+func (h *rotateHooks) load() []RotateHook {
+	if p := h.list.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+func (h *rotateHooks) add(hook RotateHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	old := h.load()
+	hooks := make([]RotateHook, 0, len(old)+1)
+	hooks = append(hooks, old...)
+	hooks = append(hooks, hook)
+	h.list.Store(&hooks)
+}
+
+// hookTracker 记录正在运行的切割钩子（包括等待压缩完成的），Close 会等待它们结束。
+// 不使用 sync.WaitGroup：Close 放弃等待后仍可能有新的切割开始计数
+type hookTracker struct {
+	mu   sync.Mutex
+	n    int
+	idle chan struct{} // n 降为 0 时关闭
+}
+
+var runningHooks hookTracker
+
+func (t *hookTracker) add() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.n == 0 {
+		t.idle = make(chan struct{})
+	}
+	t.n++
+}
+
+func (t *hookTracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.n--; t.n == 0 {
+		close(t.idle)
+	}
+}
+
+// wait 等待所有钩子结束，ctx 先结束时返回 false
+func (t *hookTracker) wait(ctx context.Context) bool {
+	t.mu.Lock()
+	if t.n == 0 {
+		t.mu.Unlock()
+		return true
+	}
+	idle := t.idle
+	t.mu.Unlock()
+	select {
+	case <-idle:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// 切割钩子中的请求（如 S3Hook 的上传）使用的 context，Close 放弃等待钩子时取消，之后的钩子使用新的 context
+var (
+	rotateHookCtxMu                 sync.Mutex
+	rotateHookCtx, rotateHookCancel = context.WithCancel(context.Background())
+)
+
+// hookContext 返回切割钩子当前使用的 context
+func hookContext() context.Context {
+	rotateHookCtxMu.Lock()
+	defer rotateHookCtxMu.Unlock()
+	return rotateHookCtx
+}
+
+// cancelRotateHooks 取消正在运行的钩子中的请求，并停止它们的重试
+func cancelRotateHooks() {
+	rotateHookCtxMu.Lock()
+	defer rotateHookCtxMu.Unlock()
+	rotateHookCancel()
+	rotateHookCtx, rotateHookCancel = context.WithCancel(context.Background())
+}
+
+// OnRotate 注册切割钩子：日志器的日志文件（file/both 模式，以及 file 类型的 sinks 和 routes）切割并压缩完成后，
+// 在后台按注册顺序调用，返回错误时重试。多个日志器共享同一个文件时，每个日志器的钩子各调用一次。
+// 会移走文件的钩子（如 ArchiveHook）应当最后注册
+func (l *LogsLogger) OnRotate(hook RotateHook) {
+	if hook == nil {
+		return
+	}
+	mu2.Lock()
+	defer mu2.Unlock()
+	l.own()
+	if l.rotateHooks == nil {
+		l.rotateHooks = &rotateHooks{}
+	}
+	l.rotateHooks.add(hook)
+}
+
+// OnRotate 为全局日志器注册切割钩子
+func OnRotate(hook RotateHook) {
+	if hook == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if globalLogger.rotateHooks == nil {
+		globalLogger.rotateHooks = &rotateHooks{}
+	}
+	globalLogger.rotateHooks.add(hook)
+}
+
+// rotated 在切割时（持有 writeMu）调用：记下此时持有该文件的日志器的钩子，
+// 在后台等切割出的文件处理完成后依次调用。之后日志器即使关闭，钩子也会执行
+func (w *sharedFileWriter) rotated(closedPath string, wait func() (string, bool)) {
+	var hooks [][]RotateHook
+	if sets := w.hooks.Load(); sets != nil {
+		for _, set := range *sets {
+			if list := set.load(); len(list) > 0 {
+				hooks = append(hooks, list)
+			}
+		}
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	runningHooks.add()
+	go func() {
+		defer runningHooks.done()
+		path, ok := wait()
+		if !ok {
+			return // 已被清理
+		}
+		for _, list := range hooks {
+			for _, hook := range list {
+				runRotateHook(hook, path)
+			}
+		}
+	}()
+}
+
+func containsHooks(sets []*rotateHooks, h *rotateHooks) bool {
+	for _, x := range sets {
+		if x == h {
+			return true
+		}
+	}
+	return false
+}
+
+// runRotateHook 调用钩子，出错时按退避时间重试，最终失败时通过 Diagnostics 通道报告；
+// Close 放弃等待时不再重试
+func runRotateHook(hook RotateHook, closedPath string) {
+	ctx := hookContext()
+	backoff := rotateHookBackoff
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		if err = callRotateHook(hook, closedPath); err == nil {
+			return
+		}
+		if attempt >= rotateHookAttempts {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+			continue
+		case <-ctx.Done():
+		}
+		break
+	}
+	reportDiagnostic(Diagnostic{
+		Source:  "rotate_hook",
+		Message: fmt.Sprintf("rotate hook failed after %d attempts", attempt),
+		Path:    closedPath,
+		Err:     err,
+	})
+}
+
+// callRotateHook 调用钩子，把 panic 当作错误处理，避免钩子中的错误导致程序退出
+func callRotateHook(hook RotateHook, closedPath string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return hook(closedPath)
+}
+
+// waitRotateHooks 等待正在运行的切割钩子结束，ctx 结束时放弃等待并取消钩子中的请求
+func waitRotateHooks(ctx context.Context) {
+	if !runningHooks.wait(ctx) {
+		cancelRotateHooks()
+	}
+}
+
+// ArchiveHook 返回把切割出的文件移到按日期分组的归档目录（dir/2006-01-02/）的钩子，日期取文件的修改时间。
+// dir 为相对路径时相对于日志文件所在目录。移走的文件不再受 MaxBackups、KeepDays 的管理
+func ArchiveHook(dir string) RotateHook {
+	return func(closedPath string) error {
+		info, err := os.Stat(closedPath)
+		if err != nil {
+			return err
+		}
+		base := dir
+		if !filepath.IsAbs(base) {
+			base = filepath.Join(filepath.Dir(closedPath), base)
+		}
+		target := filepath.Join(base, info.ModTime().Format("2006-01-02"), filepath.Base(closedPath))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return moveFile(closedPath, target)
+	}
+}
+
+// moveFile 移动文件，跨文件系统时复制后删除原文件
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	os.Chtimes(dst, info.ModTime(), info.ModTime())
+	return os.Remove(src)
+}
+
+// lumberjackBackupTimeFormat lumberjack 的切割文件名中的时间格式
+const lumberjackBackupTimeFormat = "2006-01-02T15-04-05.000"
+
+// lumberjackNoRotateSize 交给 lumberjack 的大小上限（MB），足够大，使 lumberjack 自身不会按大小切割
+const lumberjackNoRotateSize = math.MaxInt32
+
+// lumberjackWriter 包装 lumberjack：lumberjack 不通知切割，这里按大小上限自行调用 Rotate 切割，
+// 切割后找出切割出的文件，等压缩完成后通知。Write 和 Close 由调用方持有 writeMu 串行调用
+type lumberjackWriter struct {
+	*lumberjack.Logger
+	maxSize  int64 // 大小上限（字节）
+	size     int64 // 当前文件大小，-1 表示未知，下次写入时读取
+	onRotate rotateFunc
+}
+
+func newLumberjackWriter(lj *lumberjack.Logger, onRotate rotateFunc) *lumberjackWriter {
+	maxSize := lj.MaxSize
+	if maxSize == 0 {
+		maxSize = defaultRotateMaxSize
+	}
+	lj.MaxSize = lumberjackNoRotateSize
+	return &lumberjackWriter{Logger: lj, maxSize: int64(maxSize) * 1024 * 1024, size: -1, onRotate: onRotate}
+}
+
+func (w *lumberjackWriter) Write(p []byte) (int, error) {
+	if w.size < 0 {
+		w.size = 0
+		if info, err := os.Stat(w.Filename); err == nil {
+			w.size = info.Size()
+		}
+	}
+	// 超过上限的单条日志写入新文件，不再报错
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.Logger.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate 切割当前文件，比较切割前后最新的切割文件，找出这次切割出的文件
+func (w *lumberjackWriter) rotate() error {
+	before := w.newestBackup()
+	if err := w.Logger.Rotate(); err != nil {
+		return err
+	}
+	w.size = 0
+	if backup := w.newestBackup(); backup != "" && backup != before && w.onRotate != nil {
+		w.onRotate(backup, func() (string, bool) { return w.waitCompressed(backup) })
+	}
+	return nil
+}
+
+// Close 关闭文件，之后的写入会重新打开
+func (w *lumberjackWriter) Close() error {
+	w.size = -1
+	return w.Logger.Close()
+}
+
+// newestBackup 返回最新的切割文件（未压缩时的文件名），已经压缩为 .gz 的也计入。
+// 文件名中的时间使格式相同的文件名可以直接比较大小
+func (w *lumberjackWriter) newestBackup() string {
+	dir := filepath.Dir(w.Filename)
+	base := filepath.Base(w.Filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	newest := ""
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), ".gz")
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if _, err := time.Parse(lumberjackBackupTimeFormat, ts); err != nil {
+			continue
+		}
+		if name > newest {
+			newest = name
+		}
+	}
+	if newest == "" {
+		return ""
+	}
+	return filepath.Join(dir, newest)
+}
+
+// compressWindow 切割出的文件等待后台压缩完成的最长时间
+const compressWindow = time.Minute
+
+// waitCompressed 启用压缩时等待 lumberjack 在后台压缩完成（压缩后会删除原文件），返回最终的文件
+func (w *lumberjackWriter) waitCompressed(backup string) (string, bool) {
+	if w.Compress {
+		deadline := time.Now().Add(compressWindow)
+		for fileExists(backup) && time.Now().Before(deadline) {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	return finalRotatedPath(backup)
+}
+
+// finalRotatedPath 切割出的文件压缩后为 .gz 文件，两者都不存在时表示已被清理
+func finalRotatedPath(name string) (string, bool) {
+	if fileExists(name) {
+		return name, true
+	}
+	if fileExists(name + ".gz") {
+		return name + ".gz", true
+	}
+	return "", false
+}
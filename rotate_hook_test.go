@@ -0,0 +1,140 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// setRotateHookRetry 缩短切割钩子的重试间隔，测试结束后恢复
+func setRotateHookRetry(t *testing.T, attempts int) {
+	t.Helper()
+	oldAttempts, oldBackoff := rotateHookAttempts, rotateHookBackoff
+	rotateHookAttempts, rotateHookBackoff = attempts, time.Millisecond
+	t.Cleanup(func() { rotateHookAttempts, rotateHookBackoff = oldAttempts, oldBackoff })
+}
+
+// drainDiagnostics 清空诊断通道中之前的测试留下的信息
+func drainDiagnostics() {
+	for {
+		select {
+		case <-diagnostics:
+		default:
+			return
+		}
+	}
+}
+
+func TestLumberjackWriterDetectsRotation(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "app.log")
+		var rotated []string
+		w := newLumberjackWriter(&lumberjack.Logger{Filename: path, MaxSize: 1, Compress: compress},
+			func(closedPath string, wait func() (string, bool)) {
+				final, ok := wait()
+				if !ok {
+					t.Errorf("rotated file %s removed", closedPath)
+				}
+				rotated = append(rotated, final)
+			})
+
+		line := []byte(strings.Repeat("x", 600*1024) + "\n")
+		for i := 0; i < 3; i++ { // 第二、三次写入各切割一次
+			if _, err := w.Write(line); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(2 * time.Millisecond) // 切割出的文件名精确到毫秒
+		}
+		w.Close()
+
+		if len(rotated) != 2 || rotated[0] == rotated[1] {
+			t.Fatalf("compress=%v: rotated = %q, want 2 distinct files", compress, rotated)
+		}
+		for _, p := range rotated {
+			if compress != strings.HasSuffix(p, ".gz") {
+				t.Fatalf("compress=%v: rotated file %s", compress, p)
+			}
+		}
+	}
+}
+
+func TestRotateHookRetries(t *testing.T) {
+	setRotateHookRetry(t, 3)
+	drainDiagnostics()
+
+	var calls atomic.Int32
+	runRotateHook(func(string) error {
+		if calls.Add(1) < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	}, "app.log")
+	if calls.Load() != 3 {
+		t.Fatalf("calls = %d, want 3", calls.Load())
+	}
+	select {
+	case d := <-Diagnostics():
+		t.Fatalf("unexpected diagnostic: %+v", d)
+	default:
+	}
+
+	calls.Store(0)
+	runRotateHook(func(string) error {
+		calls.Add(1)
+		panic("boom")
+	}, "app.log")
+	if calls.Load() != 3 {
+		t.Fatalf("calls = %d, want 3", calls.Load())
+	}
+	select {
+	case d := <-Diagnostics():
+		if d.Source != "rotate_hook" || d.Path != "app.log" || d.Err == nil {
+			t.Fatalf("diagnostic = %+v", d)
+		}
+	default:
+		t.Fatal("final failure not reported")
+	}
+}
+
+func TestOnRotateCalledAfterClose(t *testing.T) {
+	setRotateHookRetry(t, 2)
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := NewLogger(LogConf{Mode: LogModeFile, Path: path, MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var got []string
+	failed := false
+	l.OnRotate(func(closedPath string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !failed { // 第一次失败，重试后成功
+			failed = true
+			return errors.New("temporary")
+		}
+		got = append(got, closedPath)
+		return nil
+	})
+
+	msg := strings.Repeat("x", 600*1024)
+	l.Info(msg)
+	l.Info(msg)                                           // 切割
+	if err := l.Close(context.Background()); err != nil { // 等待钩子执行完
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || filepath.Dir(got[0]) != filepath.Dir(path) || got[0] == path {
+		t.Fatalf("hook got %q", got)
+	}
+}
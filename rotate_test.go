@@ -0,0 +1,134 @@
+package logs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func loadTestLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestPeriodBounds(t *testing.T) {
+	ny := loadTestLocation(t, "America/New_York")
+	santiago := loadTestLocation(t, "America/Santiago")
+	utc := func(s string) time.Time {
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	for _, tc := range []struct {
+		name       string
+		period     string
+		t          time.Time
+		start, end time.Time
+	}{
+		{"hour end", RotateHourly, time.Date(2024, 3, 1, 10, 59, 59, 999999999, time.UTC),
+			time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)},
+		{"hour start", RotateHourly, time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"year end", RotateDaily, time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC),
+			time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"leap day", RotateDaily, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		// 夏令时结束，01:00-02:00 出现两次，各自是一个小时的周期
+		{"fall back first", RotateHourly, utc("2024-11-03T05:30:00Z").In(ny),
+			utc("2024-11-03T05:00:00Z"), utc("2024-11-03T06:00:00Z")},
+		{"fall back second", RotateHourly, utc("2024-11-03T06:30:00Z").In(ny),
+			utc("2024-11-03T06:00:00Z"), utc("2024-11-03T07:00:00Z")},
+		{"fall back day", RotateDaily, utc("2024-11-03T12:00:00Z").In(ny),
+			utc("2024-11-03T04:00:00Z"), utc("2024-11-04T05:00:00Z")},
+		{"spring forward day", RotateDaily, utc("2024-03-10T12:00:00Z").In(ny),
+			utc("2024-03-10T05:00:00Z"), utc("2024-03-11T04:00:00Z")},
+		// 圣地亚哥的夏令时从零点开始，当天没有 00:00，前一天到次日 01:00 结束
+		{"midnight gap day", RotateDaily, utc("2024-09-08T15:00:00Z").In(santiago),
+			utc("2024-09-08T04:00:00Z"), utc("2024-09-09T03:00:00Z")},
+		{"before midnight gap", RotateDaily, utc("2024-09-08T03:30:00Z").In(santiago),
+			utc("2024-09-07T04:00:00Z"), utc("2024-09-08T04:00:00Z")},
+	} {
+		r := &timeRotator{period: tc.period}
+		start, end := r.periodBounds(tc.t)
+		if !start.Equal(tc.start) || !end.Equal(tc.end) {
+			t.Errorf("%s: periodBounds(%v) = %v, %v; want %v, %v", tc.name, tc.t, start, end, tc.start, tc.end)
+		}
+		if tc.t.Before(start) || !tc.t.Before(end) {
+			t.Errorf("%s: %v not in [%v, %v)", tc.name, tc.t, start, end)
+		}
+		if start.Day() != tc.t.Day() && tc.period == RotateDaily {
+			t.Errorf("%s: period starts on day %d, want %d", tc.name, start.Day(), tc.t.Day())
+		}
+	}
+}
+
+func TestTimeRotatorBoundary(t *testing.T) {
+	dir := t.TempDir()
+	r := newTimeRotator(filepath.Join(dir, "app.log"), rotationConf{rotation: RotateHourlySize, maxSize: 1, location: time.UTC})
+	var rotated []string
+	r.onRotate = func(closedPath string, wait func() (string, bool)) {
+		final, _ := wait()
+		rotated = append(rotated, filepath.Base(final))
+	}
+	defer r.Close()
+
+	write := func(now time.Time, nextSeq bool) {
+		t.Helper()
+		var err error
+		if r.file == nil {
+			err = r.open(now, nextSeq, "")
+		} else {
+			err = r.rotate(now, nextSeq)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.file.Write([]byte("line\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	last := time.Date(2024, 12, 31, 23, 59, 59, 999999999, time.UTC)
+	write(last, false)
+	if !r.periodEnd.Equal(last.Add(time.Nanosecond)) {
+		t.Fatalf("period end = %v", r.periodEnd)
+	}
+	write(last.Add(time.Nanosecond), false) // 正好在周期结束时切换到下一个周期
+	write(last.Add(time.Minute), true)      // 同一周期内按大小切割
+	if want := []string{"app-20241231-23.log", "app-20250101-00.log"}; len(rotated) != 2 || rotated[0] != want[0] || rotated[1] != want[1] {
+		t.Fatalf("rotated = %q, want %q", rotated, want)
+	}
+	if got := filepath.Base(r.file.Name()); got != "app-20250101-00.1.log" {
+		t.Fatalf("current file = %s", got)
+	}
+	if target, err := os.Readlink(filepath.Join(dir, "app.log")); err == nil && target != "app-20250101-00.1.log" {
+		t.Fatalf("link -> %s", target)
+	}
+
+	// 重新打开时追加到该周期最新的文件；最新的文件已经压缩时使用下一个序号
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	write(last.Add(2*time.Minute), false)
+	if got := filepath.Base(r.file.Name()); got != "app-20250101-00.1.log" {
+		t.Fatalf("reopened file = %s", got)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipFile(filepath.Join(dir, "app-20250101-00.1.log")); err != nil {
+		t.Fatal(err)
+	}
+	write(last.Add(3*time.Minute), false)
+	if got := filepath.Base(r.file.Name()); got != "app-20250101-00.2.log" {
+		t.Fatalf("file after compression = %s", got)
+	}
+}
@@ -0,0 +1,131 @@
+package logs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RouteConf 将一个级别范围内的日志额外写入单独的日志文件，完整的日志仍写入主输出。
+// 范围重叠时，同一条日志会写入多个文件
+type RouteConf struct {
+	Levels     string `yaml:"levels"`      // 级别范围：">=error"、">warn"、"<=info"、"debug"（仅该级别）、"info..error"（闭区间）
+	Path       string `yaml:"path"`        // 日志文件路径
+	Encoding   string `yaml:"encoding"`    // 编码，为空时使用日志器的编码
+	MaxSize    int    `yaml:"max_size"`    // 切割参数，为 0 时使用 LogConf 中的值
+	MaxBackups int    `yaml:"max_backups"` // 同上
+	KeepDays   int    `yaml:"keep_days"`   // 同上
+	Compress   bool   `yaml:"compress"`    // 为 false 时使用 LogConf 中的值
+
+	Rotation    string `yaml:"rotation"`     // 切割策略，为空时使用 LogConf 中的值
+	FilePattern string `yaml:"file_pattern"` // 按时间切割时的文件名，为空时由 Path 生成
+}
+
+func (c RouteConf) String() string {
+	return c.Levels + " -> " + c.Path
+}
+
+// sinkConf 路由对应的文件输出配置，名称为文件路径
+func (c RouteConf) sinkConf() SinkConf {
+	return SinkConf{
+		Type:       SinkFile,
+		Path:       c.Path,
+		Encoding:   c.Encoding,
+		MaxSize:    c.MaxSize,
+		MaxBackups: c.MaxBackups,
+		KeepDays:   c.KeepDays,
+		Compress:   c.Compress,
+
+		Rotation:    c.Rotation,
+		FilePattern: c.FilePattern,
+	}
+}
+
+// parseLevelRange 解析级别范围，返回闭区间 [min, max]
+func parseLevelRange(spec string) (min, max LogLevel, err error) {
+	spec = strings.TrimSpace(spec)
+	min, max = LogLevelDebug, LogLevelPanic
+
+	if lo, hi, ok := strings.Cut(spec, ".."); ok {
+		if min, err = ParseLogLevel(strings.TrimSpace(lo)); err != nil {
+			return 0, 0, err
+		}
+		if max, err = ParseLogLevel(strings.TrimSpace(hi)); err != nil {
+			return 0, 0, err
+		}
+	} else {
+		rest := strings.TrimLeft(spec, "<>=")
+		op := spec[:len(spec)-len(rest)]
+		level, err := ParseLogLevel(strings.TrimSpace(rest))
+		if err != nil {
+			return 0, 0, err
+		}
+		switch op {
+		case ">=":
+			min = level
+		case ">":
+			min = level + 1
+		case "<=":
+			max = level
+		case "<":
+			max = level - 1
+		case "", "=", "==":
+			min, max = level, level
+		default:
+			return 0, 0, fmt.Errorf("invalid level range %q", spec)
+		}
+	}
+
+	if min > max {
+		return 0, 0, fmt.Errorf("empty level range %q", spec)
+	}
+	return min, max, nil
+}
+
+// validateRouteConf 校验路由配置，返回出错的配置项
+func validateRouteConf(c RouteConf) (field string, err error) {
+	if _, _, err := parseLevelRange(c.Levels); err != nil {
+		return "levels", err
+	}
+	return validateSinkConf(c.sinkConf())
+}
+
+// openRoutes 为 conf.Routes 中的每条路由打开日志文件，出错时释放已经打开的文件
+func openRoutes(conf LogConf, hooks *rotateHooks) ([]*Sink, error) {
+	sinks := make([]*Sink, 0, len(conf.Routes))
+	for i, c := range conf.Routes {
+		field, err := validateRouteConf(c)
+		if err == nil {
+			field, err = validateSinkRotation(c.sinkConf(), conf)
+		}
+		if err != nil {
+			releaseSinks(sinks)
+			return nil, &ConfError{Field: fmt.Sprintf("routes[%d].%s", i, field), Err: err}
+		}
+		min, max, _ := parseLevelRange(c.Levels)
+		s, err := openSink(c.sinkConf(), conf, hooks)
+		if err != nil {
+			releaseSinks(sinks)
+			return nil, err
+		}
+		s.level.SetLevel(min)
+		s.maxLevel = max
+		s.route = true
+		sinks = append(sinks, s)
+	}
+	return sinks, nil
+}
+
+// routeSinks 返回当前输出目标中由路由创建的部分
+func (l *LogsLogger) routeSinks() []*Sink {
+	ss := l.sinks.Load()
+	if ss == nil {
+		return nil
+	}
+	var routes []*Sink
+	for _, s := range ss.load() {
+		if s.route {
+			routes = append(routes, s)
+		}
+	}
+	return routes
+}
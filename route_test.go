@@ -0,0 +1,122 @@
+package logs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setUpGlobal 用 conf 配置全局日志器，测试结束后恢复默认配置
+func setUpGlobal(t *testing.T, conf LogConf) {
+	t.Helper()
+	if err := SetUp(conf); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := SetUp(DefaultLogConf()); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// 包级函数按各自的级别写入路由文件，范围重叠时写入多个文件
+func TestGlobalRoutesByLevel(t *testing.T) {
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+	setUpGlobal(t, LogConf{
+		Mode: LogModeFile,
+		Path: path("app.log"),
+		Routes: []RouteConf{
+			{Levels: ">=error", Path: path("error.log")},
+			{Levels: "warn..error", Path: path("warn.log")},
+			{Levels: "debug", Path: path("debug.log")},
+		},
+	})
+	if err := SetLogLevel(LogLevelDebug); err != nil { // Level 为 0 时使用默认的 info
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		log   func(msg string)
+		msg   string
+		level string
+		files []string
+	}{
+		{func(m string) { Debug(m) }, "debug-msg", "[DEBUG]", []string{"app.log", "debug.log"}},
+		{func(m string) { Info(m) }, "info-msg", "[INFO]", []string{"app.log"}},
+		{func(m string) { Infof("%s", m) }, "infof-msg", "[INFO]", []string{"app.log"}},
+		{func(m string) { Warn(m) }, "warn-msg", "[WARN]", []string{"app.log", "warn.log"}},
+		{func(m string) { Warnf("%s", m) }, "warnf-msg", "[WARN]", []string{"app.log", "warn.log"}},
+		{func(m string) { Warnw(m) }, "warnw-msg", "[WARN]", []string{"app.log", "warn.log"}},
+		{func(m string) { Error(m) }, "error-msg", "[ERROR]", []string{"app.log", "error.log", "warn.log"}},
+		{func(m string) { Errorf("%s", m) }, "errorf-msg", "[ERROR]", []string{"app.log", "error.log", "warn.log"}},
+	} {
+		tc.log(tc.msg)
+		for _, name := range []string{"app.log", "error.log", "warn.log", "debug.log"} {
+			var line string
+			b, _ := os.ReadFile(path(name))
+			for _, l := range strings.Split(string(b), "\n") {
+				if strings.Contains(l, tc.msg) {
+					line = l
+				}
+			}
+			want := false
+			for _, f := range tc.files {
+				want = want || f == name
+			}
+			if (line != "") != want {
+				t.Fatalf("%s in %s: got %q, want written=%v", tc.msg, name, line, want)
+			}
+			if want && !strings.Contains(line, tc.level+" ") {
+				t.Fatalf("%s in %s: line %q, want level %s", tc.msg, name, line, tc.level)
+			}
+		}
+	}
+}
+
+// NewLogger 创建的日志器同样按级别写入路由文件，低于日志器级别的日志不写入任何文件
+func TestLoggerRoutesByLevel(t *testing.T) {
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+	l, err := NewLogger(LogConf{
+		Mode:  LogModeFile,
+		Path:  path("app.log"),
+		Level: int(LogLevelWarn),
+		Routes: []RouteConf{
+			{Levels: ">=error", Path: path("error.log")},
+			{Levels: "info..warn", Path: path("warn.log")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close(context.Background())
+
+	l.Info("info-msg") // 低于日志器级别，不写入任何文件
+	l.Warnf("%s", "warn-msg")
+	l.Errorw("error-msg", "k", "v")
+
+	for _, tc := range []struct {
+		file string
+		want []string
+		not  []string
+	}{
+		{"app.log", []string{"[WARN] ", "warn-msg", "[ERROR] ", "error-msg k=v"}, []string{"info-msg"}},
+		{"error.log", []string{"error-msg k=v"}, []string{"info-msg", "warn-msg"}},
+		{"warn.log", []string{"warn-msg"}, []string{"info-msg", "error-msg"}},
+	} {
+		got := readFile(t, path(tc.file))
+		for _, s := range tc.want {
+			if !strings.Contains(got, s) {
+				t.Errorf("%s: missing %q in %q", tc.file, s, got)
+			}
+		}
+		for _, s := range tc.not {
+			if strings.Contains(got, s) {
+				t.Errorf("%s: unexpected %q in %q", tc.file, s, got)
+			}
+		}
+	}
+}
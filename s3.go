@@ -0,0 +1,200 @@
+package logs
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Options S3Hook 的选项，兼容 AWS S3 以及 MinIO 等 S3 协议的对象存储
+type S3Options struct {
+	Endpoint  string       // 服务地址，如 "https://s3.us-east-1.amazonaws.com"、"http://127.0.0.1:9000"
+	Bucket    string       // 存储桶，使用路径形式的地址：Endpoint/Bucket/对象名
+	Prefix    string       // 对象名前缀，如 "logs/app/"；对象名为 Prefix 加文件名
+	Region    string       // 区域，为空时为 us-east-1
+	AccessKey string       // 为空时不签名（匿名上传）
+	SecretKey string       // 与 AccessKey 对应的密钥
+	Client    *http.Client // 为空时使用超时为 s3Timeout 的客户端；上传大文件需要更长时间时自行设置
+}
+
+// s3Timeout 默认客户端一次上传的超时时间，服务无响应时不会让 Close 一直等待
+const s3Timeout = 5 * time.Minute
+
+var s3DefaultClient = &http.Client{Timeout: s3Timeout}
+
+// S3Hook 返回把切割出的文件上传（PUT）到 S3 兼容存储的钩子，请求使用 AWS 签名 V4。
+// 上传后不删除本地文件，需要时可以在它之后注册 ArchiveHook 或由 MaxBackups、KeepDays 清理。
+// Close 的 ctx 结束时，正在进行的上传被取消
+func S3Hook(opts S3Options) RotateHook {
+	return func(closedPath string) error {
+		return s3Put(hookContext(), opts, closedPath)
+	}
+}
+
+func s3Put(ctx context.Context, opts S3Options, path string) error {
+	if opts.Endpoint == "" || opts.Bucket == "" {
+		return errors.New("s3 endpoint and bucket are required")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	payloadHash := hex.EncodeToString(h.Sum(nil))
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(strings.TrimRight(opts.Endpoint, "/"))
+	if err != nil {
+		return err
+	}
+	key := opts.Prefix + filepath.Base(path)
+	u.Path += "/" + opts.Bucket + "/" + key
+	u.RawPath = s3EscapePath(u.Path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if opts.AccessKey != "" {
+		region := opts.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		signV4(req, payloadHash, region, "s3", opts.AccessKey, opts.SecretKey, time.Now())
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = s3DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("s3 put %s/%s: %s: %s", opts.Bucket, key, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// signV4 按 AWS 签名 V4 为请求添加 Authorization 头，签名 host 和请求中已有的所有请求头
+func signV4(req *http.Request, payloadHash, region, service, accessKey, secretKey string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex(canonicalRequest)}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery 按参数名排序并编码查询参数
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), q[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3EscapePath 按 S3 的规则编码对象路径，保留 /
+func s3EscapePath(path string) string {
+	return s3Escape(path, false)
+}
+
+// s3Escape 除 A-Z a-z 0-9 - _ . ~ 以外的字节都编码为 %XX；escapeSlash 为 false 时保留 /
+func s3Escape(s string, escapeSlash bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !escapeSlash) {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+	return sb.String()
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -7,8 +7,6 @@ import (
 	"log"
 	"os"
 	"reflect"
-
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // InitLogger 初始化日志记录器
@@ -28,7 +26,7 @@ func initLoggers(output io.Writer) {
 		}
 	}
 
-	var multiWriter io.Writer
+	multiWriter := output
 	if output != os.Stderr {
 		multiWriter = io.MultiWriter(os.Stderr, output)
 	}
@@ -40,38 +38,33 @@ func initLoggers(output io.Writer) {
 	globalLogger.errorL = log.New(output, "[ERROR] ", flags)
 	globalLogger.fatalL = log.New(multiWriter, "[FATAL] ", flags)
 	globalLogger.panicL = log.New(multiWriter, "[PANIC] ", flags)
+	globalLogger.publish()
 }
 
 // initFileLog 初始化日志文件输出
 func initFileLog(logFilePath string) {
-	if fileLogger == nil {
-		fileLogger = &lumberjack.Logger{}
-	}
-	fileLogger.Filename = logFilePath
-	fileLogger.MaxSize = globalLogger.logConf.MaxSize
-	fileLogger.MaxBackups = globalLogger.logConf.MaxBackups
-	fileLogger.MaxAge = globalLogger.logConf.KeepDays
-	fileLogger.Compress = globalLogger.logConf.Compress
+	// 每个日志器持有自己的文件写入器引用，相同路径的日志器共享同一个写入器
+	handle := acquireFileWriter(logFilePath, globalLogger.logConf, globalLogger.rotateHooks)
+	globalLogger.fileHandle.release()
+	globalLogger.fileHandle = handle
+
+	// 文件中不写入颜色代码
+	fileWriter := &noColorWriter{w: handle}
 
-	globalLogger.output = fileLogger
+	globalLogger.output = fileWriter
 	// 重新初始化所有日志器
-	initLoggers(fileLogger)
+	initLoggers(fileWriter)
 }
 
 // initMultiWriter 初始化同时输出到控制台和文件的日志器
 func initMultiWriter(logFilePath string) {
 
-	if fileLogger == nil {
-		fileLogger = &lumberjack.Logger{}
-	}
-	fileLogger.Filename = logFilePath
-	fileLogger.MaxSize = globalLogger.logConf.MaxSize
-	fileLogger.MaxBackups = globalLogger.logConf.MaxBackups
-	fileLogger.MaxAge = globalLogger.logConf.KeepDays
-	fileLogger.Compress = globalLogger.logConf.Compress
+	handle := acquireFileWriter(logFilePath, globalLogger.logConf, globalLogger.rotateHooks)
+	globalLogger.fileHandle.release()
+	globalLogger.fileHandle = handle
 
 	// 创建一个同时写入控制台和文件的 Writer
-	multiWriter := io.MultiWriter(os.Stdout, fileLogger)
+	multiWriter := io.MultiWriter(os.Stdout, &noColorWriter{w: handle})
 
 	globalLogger.output = multiWriter
 	// 重新初始化所有日志器
@@ -79,81 +72,18 @@ func initMultiWriter(logFilePath string) {
 }
 
 // 设置方法 -----------------------------------------------------------------------
-// SetUp 初始化日志记录器
+// SetUp 初始化日志记录器，配置无效时返回错误，全局日志器保持原样
 func SetUp(logConf LogConf) error {
 	mu.Lock()
-	defer mu.Unlock()
-
-	// 检查日志配置是否有效
-	if logConf.Mode == "" {
-		logConf.Mode = defaultLogConf.Mode
-	}
-	if logConf.Level == 0 {
-		logConf.Level = defaultLogConf.Level
-	}
-	if logConf.Encoding == "" {
-		logConf.Encoding = defaultLogConf.Encoding
-	}
-	if logConf.MaxSize == 0 {
-		logConf.MaxSize = defaultLogConf.MaxSize
-	}
-	if logConf.MaxBackups == 0 {
-		logConf.MaxBackups = defaultLogConf.MaxBackups
-	}
-	if logConf.KeepDays == 0 {
-		logConf.KeepDays = defaultLogConf.KeepDays
-	}
-	if logConf.Path == "" {
-		logConf.Path = defaultLogConf.Path
-	}
-
-	globalLogger.logConf = logConf
-	globalLogger.logFlags = LogFlagsCommon
-	globalLogger.hasRootFilePrefix = false
-	globalLogger.logWriteStrategy = LoggingSync
-
-	if globalLogger.logConf.Mode == "file" || globalLogger.logConf.Mode == "both" {
-		if globalLogger.logConf.Path == "" {
-			return errors.New("log path is required")
-		}
-	}
-
-	// 设置编码
-	switch logConf.Encoding {
-	case LogEncodingPlain:
-		globalLogger.encoder = &PlainEncoder{}
-	case LogEncodingJSON:
-		globalLogger.encoder = &JsonEncoder{}
-	default:
-		return fmt.Errorf("unsupported log encoding: %s", logConf.Encoding)
-	}
-
-	// 设置日志级别
-	if LogLevel(logConf.Level) < LogLevelDebug {
-		return errors.New("invalid log level")
-	}
-
-	// currentLogLevel = LogLevel(logConf.Level)
-
-	// 获取项目根目录
-	projectRootOnce.Do(func() {
-		var err error
-		projectRoot, err = findProjectRoot()
-		if err != nil {
-			projectRoot = "" // 如果找不到，则不使用相对路径
-		}
-	})
-
-	// 初始化输出
-	switch globalLogger.logConf.Mode {
-	case "file":
-		initFileLog(globalLogger.logConf.Path)
-	case "both":
-		initMultiWriter(globalLogger.logConf.Path)
-	default:
-		initLoggers(os.Stdout)
+	s, err := globalLogger.prepareSetUp(logConf)
+	if err != nil {
+		mu.Unlock()
+		return err
 	}
+	r := globalLogger.applySetUp(s)
+	mu.Unlock()
 
+	r.release()
 	return nil
 }
 
@@ -246,26 +176,74 @@ func SetOutput(writer io.Writer) error {
 
 	fmt.Println("mode：", mode)
 	globalLogger.logConf.Mode = mode
+	// 不再使用之前的日志文件和输出目标
+	globalLogger.fileHandle.release()
+	globalLogger.fileHandle = nil
 	initLoggers(globalLogger.output)
+	globalLogger.logConf.Sinks = nil
+	globalLogger.setSinks(append([]*Sink{modeSink(mode)}, globalLogger.routeSinks()...))
 
 	return nil
 }
 
 // 设置编码
 func SetEncoding(encoding string) error {
-	// LogEncodingJSON、LOgEncodingPlain
+	// LogEncodingJSON、LogEncodingPlain、LogEncodingLogfmt
 	mu.Lock()
 	defer mu.Unlock()
+
+	encoder, err := newEncoder(encoding)
+	if err != nil {
+		return err
+	}
 	globalLogger.logConf.Encoding = encoding
+	globalLogger.encoder = encoder
+	globalLogger.publish()
+	return nil
+}
 
-	switch encoding {
-	case LogEncodingPlain:
-		globalLogger.encoder = &PlainEncoder{}
-	case LogEncodingJSON:
-		globalLogger.encoder = &JsonEncoder{}
-	default:
-		return fmt.Errorf("unsupported log encoding: %s", encoding)
+// SetEncoder 设置自定义编码器，旧的 Encoder 会通过 AdaptEncoder 适配
+func SetEncoder(encoder Encoder) error {
+	if encoder == nil {
+		return errors.New("encoder cannot be nil")
 	}
+	return SetRecordEncoder(AdaptEncoder(encoder))
+}
+
+// SetRecordEncoder 设置接收完整日志记录的编码器
+func SetRecordEncoder(encoder RecordEncoder) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if encoder == nil {
+		return errors.New("encoder cannot be nil")
+	}
+	globalLogger.encoder = encoder
+	globalLogger.publish()
+	return nil
+}
+
+// SetTimeFormat 设置时间格式：Go 时间布局或预设（rfc3339、rfc3339nano、iso8601、epoch、epoch_ms 等），
+// 传入空字符串时恢复为由 Ldate、Ltime 等标志控制
+func SetTimeFormat(format string) {
+	mu.Lock()
+	defer mu.Unlock()
+	globalLogger.logConf.TimeFormat = format
+	globalLogger.publish()
+}
+
+// SetTimeZone 设置日志时间的时区，如 "UTC"、"Asia/Shanghai"
+func SetTimeZone(name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	loc, err := loadTimeZone(name)
+	if err != nil {
+		return err
+	}
+	globalLogger.logConf.TimeZone = name
+	globalLogger.timeLocation = loc
+	globalLogger.publish()
 	return nil
 }
 
@@ -274,8 +252,13 @@ func SetMaxSize(maxSize int) {
 	mu.Lock()
 	defer mu.Unlock()
 	globalLogger.logConf.MaxSize = maxSize
+	globalLogger.publish()
 
 	// 重新初始化日志器以应用新设置
+	globalLogger.applySinkRotation()
+	if len(globalLogger.logConf.Sinks) > 0 {
+		return // 设置了 sinks 时 Mode 不再生效
+	}
 	if globalLogger.logConf.Mode == "file" {
 		initFileLog(globalLogger.logConf.Path)
 	} else if globalLogger.logConf.Mode == "both" {
@@ -288,8 +271,13 @@ func SetMaxAge(maxAge int) {
 	mu.Lock()
 	defer mu.Unlock()
 	globalLogger.logConf.KeepDays = maxAge
+	globalLogger.publish()
 
 	// 重新初始化日志器以应用新设置
+	globalLogger.applySinkRotation()
+	if len(globalLogger.logConf.Sinks) > 0 {
+		return // 设置了 sinks 时 Mode 不再生效
+	}
 	if globalLogger.logConf.Mode == "file" {
 		initFileLog(globalLogger.logConf.Path)
 	} else if globalLogger.logConf.Mode == "both" {
@@ -302,7 +290,30 @@ func SetMaxBackups(maxBackups int) {
 	mu.Lock()
 	defer mu.Unlock()
 	globalLogger.logConf.MaxBackups = maxBackups
+	globalLogger.publish()
+
+	globalLogger.applySinkRotation()
+	if len(globalLogger.logConf.Sinks) > 0 {
+		return // 设置了 sinks 时 Mode 不再生效
+	}
+	if globalLogger.logConf.Mode == "file" {
+		initFileLog(globalLogger.logConf.Path)
+	} else if globalLogger.logConf.Mode == "both" {
+		initMultiWriter(globalLogger.logConf.Path)
+	}
+}
 
+// 设置是否压缩切割后的日志文件
+func SetCompress(compress bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	globalLogger.logConf.Compress = compress
+	globalLogger.publish()
+
+	globalLogger.applySinkRotation()
+	if len(globalLogger.logConf.Sinks) > 0 {
+		return // 设置了 sinks 时 Mode 不再生效
+	}
 	if globalLogger.logConf.Mode == "file" {
 		initFileLog(globalLogger.logConf.Path)
 	} else if globalLogger.logConf.Mode == "both" {
@@ -320,6 +331,10 @@ func SetLogLevel(level LogLevel) error {
 	}
 
 	globalLogger.logConf.Level = int(level)
+	if a := globalLogger.level.Load(); a != nil {
+		return a.SetLevel(level)
+	}
+	globalLogger.level.Store(NewAtomicLevel(level))
 	return nil
 }
 
@@ -363,14 +378,25 @@ func SetFlags(flags int) error {
 	globalLogger.errorL.SetFlags(flags)
 	globalLogger.fatalL.SetFlags(flags)
 	globalLogger.panicL.SetFlags(flags)
+	globalLogger.publish()
 	return nil
 }
 
 // 设置日志同步还是异步
 func SetLogWriteStrategy(strategy logWriteStrategy) {
 	mu.Lock()
-	defer mu.Unlock()
 	globalLogger.logWriteStrategy = strategy
+
+	// 按配置创建异步队列，配置有变化时重建
+	var r *retiredOutput
+	if strategy == LoggingAsync {
+		if globalLogger.queue == nil || !globalLogger.queue.matches(globalLogger.logConf) {
+			r = globalLogger.restartQueue()
+		}
+	}
+	globalLogger.publish()
+	mu.Unlock()
+	r.release()
 }
 
 // 设置前缀
@@ -0,0 +1,44 @@
+package logs
+
+import (
+	"sync"
+)
+
+// signalMu 串行化信号处理，避免连续信号交错修改级别
+var signalMu sync.Mutex
+
+// reopenOnSignal 收到 SIGHUP 时重新打开所有日志文件并记录一条日志
+func reopenOnSignal(sig string) {
+	signalMu.Lock()
+	defer signalMu.Unlock()
+
+	if err := ReopenFiles(); err != nil {
+		emitUnfiltered(globalLogger, LogLevelError, "logs: reopen log files failed", F("signal", sig), F("error", err))
+		return
+	}
+	emitUnfiltered(globalLogger, LogLevelInfo, "logs: log files reopened", F("signal", sig))
+}
+
+// shiftLevelOnSignal 将全局日志器的级别升高（delta > 0）或降低（delta < 0）一级，
+// 已经是 Debug 或 Panic 时保持不变，并记录级别变化
+func shiftLevelOnSignal(sig string, delta int) {
+	signalMu.Lock()
+	defer signalMu.Unlock()
+
+	a := GetAtomicLevel()
+	from := a.Level()
+	to := from + LogLevel(delta)
+	if to < LogLevelDebug {
+		to = LogLevelDebug
+	}
+	if to > LogLevelPanic {
+		to = LogLevelPanic
+	}
+	if to == from {
+		emitUnfiltered(globalLogger, LogLevelInfo, "logs: log level unchanged", F("signal", sig), F("level", from.String()))
+		return
+	}
+	a.SetLevel(to)
+
+	emitUnfiltered(globalLogger, LogLevelInfo, "logs: log level changed", F("signal", sig), F("from", from.String()), F("to", to.String()))
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package logs
+
+// HandleSignals 当前平台不支持 SIGHUP、SIGUSR1、SIGUSR2，不做任何处理；
+// 可以直接调用 ReopenFiles 和 GetAtomicLevel().SetLevel 达到同样的效果
+func HandleSignals() (stop func()) {
+	return func() {}
+}
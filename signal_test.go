@@ -0,0 +1,43 @@
+package logs
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 信号调整级别时在 debug 和 panic 处停止，不循环到另一端
+func TestShiftLevelOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	setUpGlobal(t, LogConf{Mode: LogModeFile, Path: path})
+
+	for _, tc := range []struct {
+		from  LogLevel
+		delta int
+		want  LogLevel
+		log   string
+	}{
+		{LogLevelInfo, 1, LogLevelWarn, "logs: log level changed signal=SIGUSR1 from=info to=warn"},
+		{LogLevelInfo, -1, LogLevelDebug, "logs: log level changed signal=SIGUSR2 from=info to=debug"},
+		{LogLevelFatal, 1, LogLevelPanic, "from=fatal to=panic"},
+		{LogLevelPanic, 1, LogLevelPanic, "logs: log level unchanged signal=SIGUSR1 level=panic"},
+		{LogLevelDebug, -1, LogLevelDebug, "logs: log level unchanged signal=SIGUSR2 level=debug"},
+	} {
+		if err := SetLogLevel(tc.from); err != nil {
+			t.Fatal(err)
+		}
+		sig := "SIGUSR1"
+		if tc.delta < 0 {
+			sig = "SIGUSR2"
+		}
+		shiftLevelOnSignal(sig, tc.delta)
+
+		if got := GetAtomicLevel().Level(); got != tc.want {
+			t.Fatalf("%v %+d: level = %v, want %v", tc.from, tc.delta, got, tc.want)
+		}
+		lines := strings.Split(strings.TrimSpace(readFile(t, path)), "\n")
+		if last := lines[len(lines)-1]; !strings.Contains(last, tc.log) {
+			t.Fatalf("%v %+d: logged %q, want %q", tc.from, tc.delta, last, tc.log)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+//go:build unix
+
+package logs
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// HandleSignals 开始处理以下信号（需要主动调用）：
+//
+//	SIGHUP   重新打开所有日志文件，配合 logrotate 使用
+//	SIGUSR1  全局日志器的级别升高一级（如 info -> warn），panic 之后回到 debug
+//	SIGUSR2  全局日志器的级别降低一级（如 info -> debug），debug 之后回到 panic
+//
+// 每次处理都会输出一条日志。返回的函数用于停止处理，可以多次调用
+func HandleSignals() (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-ch:
+				switch sig {
+				case syscall.SIGHUP:
+					reopenOnSignal("SIGHUP")
+				case syscall.SIGUSR1:
+					shiftLevelOnSignal("SIGUSR1", 1)
+				case syscall.SIGUSR2:
+					shiftLevelOnSignal("SIGUSR2", -1)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,437 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// 输出目标类型
+const (
+	SinkStdout = "stdout" // 标准输出，也可以写作 console
+	SinkStderr = "stderr" // 标准错误
+	SinkFile   = "file"   // 日志文件，按 LogConf 的参数切割
+)
+
+// SinkConf 一个输出目标的配置，见 LogConf.Sinks
+type SinkConf struct {
+	Name       string `yaml:"name"`        // 名称，用于 RemoveSink，为空时使用类型（文件为路径）
+	Type       string `yaml:"type"`        // 输出类型：stdout/stderr/file
+	Level      int    `yaml:"level"`       // 最低级别，默认 debug，即只受日志器自身级别的限制
+	Encoding   string `yaml:"encoding"`    // 编码：plain/json/logfmt/console，为空时使用日志器的编码
+	Path       string `yaml:"path"`        // 日志文件路径（仅 file 类型）
+	MaxSize    int    `yaml:"max_size"`    // 切割参数（仅 file 类型），为 0 时使用 LogConf 中的值
+	MaxBackups int    `yaml:"max_backups"` // 同上
+	KeepDays   int    `yaml:"keep_days"`   // 同上
+	Compress   bool   `yaml:"compress"`    // 为 false 时使用 LogConf 中的值
+
+	Rotation    string `yaml:"rotation"`     // 切割策略，为空时使用 LogConf 中的值
+	FilePattern string `yaml:"file_pattern"` // 按时间切割时的文件名，为空时由 Path 生成（不使用 LogConf 中的值）
+}
+
+func (c SinkConf) String() string {
+	var sb strings.Builder
+	sb.WriteString(sinkName(c))
+	sb.WriteString("(" + c.Type)
+	if c.Type == SinkFile && c.Name != "" {
+		sb.WriteString(" " + c.Path)
+	}
+	sb.WriteString(" level=" + LogLevel(c.Level).String())
+	if c.Encoding != "" {
+		sb.WriteString(" encoding=" + c.Encoding)
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// Sink 日志器的一个输出目标，拥有独立的最低级别、编码器和 Writer。
+// 日志先经过日志器自身的级别（以及名称级别、vmodule）过滤，再按各输出目标的级别分发
+type Sink struct {
+	name     string
+	level    *AtomicLevel
+	maxLevel LogLevel      // 最高级别，只有路由创建的输出目标会设置为低于 panic 的值
+	route    bool          // 由 LogConf.Routes 创建，SetOutput 替换主输出时保留
+	encoder  RecordEncoder // 为空时使用日志器的编码器
+	writer   io.Writer     // 为空时表示 Mode 对应的输出（按级别写入 log.Logger 的 Writer）
+	std      bool          // 标准输出/错误流，与 console 模式一样总是同步写入
+	file     *fileHandle   // file 类型持有的文件引用，最后一个引用释放时释放
+	refs     atomic.Int32  // 包含它的 sinkSet 数量，父子日志器各自的 sinkSet 都持有引用
+	conf     *SinkConf     // 由配置创建时的配置，日志器的切割参数变化时使用
+}
+
+// NewSink 创建写入 w 的输出目标，encoder 为空时使用日志器的编码器
+func NewSink(name string, w io.Writer, level LogLevel, encoder RecordEncoder) (*Sink, error) {
+	if name == "" {
+		return nil, errors.New("sink name cannot be empty")
+	}
+	if w == nil {
+		return nil, errors.New("writer cannot be nil")
+	}
+	a := &AtomicLevel{}
+	if err := a.SetLevel(level); err != nil {
+		return nil, err
+	}
+	return &Sink{name: name, level: a, maxLevel: LogLevelPanic, encoder: encoder, writer: w, std: isStdStream(w)}, nil
+}
+
+// Name 返回输出目标的名称
+func (s *Sink) Name() string {
+	return s.name
+}
+
+// enabled 该级别的日志是否写入这个输出目标
+func (s *Sink) enabled(level LogLevel) bool {
+	return s.level.Enabled(level) && level <= s.maxLevel
+}
+
+// Level 返回输出目标的最低级别
+func (s *Sink) Level() LogLevel {
+	return s.level.Level()
+}
+
+// SetLevel 修改输出目标的最低级别，立即生效
+func (s *Sink) SetLevel(level LogLevel) error {
+	return s.level.SetLevel(level)
+}
+
+// modeSink Mode 对应的预设输出，名称为模式名：console/file/both
+func modeSink(mode string) *Sink {
+	return &Sink{name: mode, level: NewAtomicLevel(LogLevelDebug), maxLevel: LogLevelPanic}
+}
+
+// sinkType 转换输出类型，console 视为 stdout
+func sinkType(s string) (string, error) {
+	switch t := strings.ToLower(strings.TrimSpace(s)); t {
+	case SinkStdout, SinkStderr, SinkFile:
+		return t, nil
+	case "console":
+		return SinkStdout, nil
+	default:
+		return "", fmt.Errorf("unknown sink type: %q", s)
+	}
+}
+
+// sinkName 返回输出目标的名称，未设置时使用类型，文件使用路径
+func sinkName(c SinkConf) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	if t, _ := sinkType(c.Type); t == SinkFile {
+		return c.Path
+	}
+	return c.Type
+}
+
+// validateSinkConf 校验输出目标的配置，返回出错的配置项
+func validateSinkConf(c SinkConf) (field string, err error) {
+	t, err := sinkType(c.Type)
+	if err != nil {
+		return "type", err
+	}
+	if t == SinkFile && c.Path == "" {
+		return "path", errors.New("log path is required for file sink")
+	}
+	if LogLevel(c.Level) < LogLevelDebug || LogLevel(c.Level) > LogLevelPanic {
+		return "level", fmt.Errorf("unknown log level: %d", c.Level)
+	}
+	if c.Encoding != "" {
+		if _, err := newEncoder(c.Encoding); err != nil {
+			return "encoding", err
+		}
+	}
+	if c.MaxSize < 0 {
+		return "max_size", fmt.Errorf("must not be negative: %d", c.MaxSize)
+	}
+	if c.MaxBackups < 0 {
+		return "max_backups", fmt.Errorf("must not be negative: %d", c.MaxBackups)
+	}
+	if c.KeepDays < 0 {
+		return "keep_days", fmt.Errorf("must not be negative: %d", c.KeepDays)
+	}
+	// 文件名模式依赖继承的切割策略，由 validateSinkRotation 校验
+	if _, _, err := parseRotation(c.Rotation); err != nil {
+		return "rotation", err
+	}
+	return "", nil
+}
+
+// sinkRotation 返回文件输出的切割参数，未设置的参数使用 base 中的值
+func sinkRotation(c SinkConf, base LogConf) LogConf {
+	if c.MaxSize != 0 {
+		base.MaxSize = c.MaxSize
+	}
+	if c.MaxBackups != 0 {
+		base.MaxBackups = c.MaxBackups
+	}
+	if c.KeepDays != 0 {
+		base.KeepDays = c.KeepDays
+	}
+	base.Compress = base.Compress || c.Compress
+	if c.Rotation != "" {
+		base.Rotation = c.Rotation
+	}
+	base.FilePattern = c.FilePattern
+	return base
+}
+
+// validateSinkRotation 校验文件输出继承 base 之后的切割策略和文件名模式
+func validateSinkRotation(c SinkConf, base LogConf) (field string, err error) {
+	if t, _ := sinkType(c.Type); t != SinkFile {
+		return "", nil
+	}
+	rot := sinkRotation(c, base)
+	return validateRotation(rot.Rotation, rot.FilePattern, c.Path)
+}
+
+// openSink 按配置创建输出目标，file 类型会打开（或共享）日志文件
+func openSink(c SinkConf, base LogConf, hooks *rotateHooks) (*Sink, error) {
+	if field, err := validateSinkConf(c); err != nil {
+		return nil, &ConfError{Field: "sinks." + field, Err: err}
+	}
+	if field, err := validateSinkRotation(c, base); err != nil {
+		return nil, &ConfError{Field: "sinks." + field, Err: err}
+	}
+	t, _ := sinkType(c.Type)
+	c.Type = t
+
+	s := &Sink{name: sinkName(c), level: NewAtomicLevel(LogLevel(c.Level)), maxLevel: LogLevelPanic, conf: &c}
+	if c.Encoding != "" {
+		s.encoder, _ = newEncoder(c.Encoding)
+	}
+	switch t {
+	case SinkStdout:
+		s.writer, s.std = os.Stdout, true
+	case SinkStderr:
+		s.writer, s.std = os.Stderr, true
+	case SinkFile:
+		s.file = acquireFileWriter(c.Path, sinkRotation(c, base), hooks)
+		s.writer = &noColorWriter{w: s.file} // 文件中不写入颜色代码
+	}
+	return s, nil
+}
+
+// openSinks 创建 conf.Sinks 中的所有输出目标，出错时释放已经打开的文件
+func openSinks(conf LogConf, hooks *rotateHooks) ([]*Sink, error) {
+	sinks := make([]*Sink, 0, len(conf.Sinks))
+	names := make(map[string]bool, len(conf.Sinks))
+	for _, c := range conf.Sinks {
+		s, err := openSink(c, conf, hooks)
+		if err == nil && names[s.name] {
+			s.release()
+			err = fmt.Errorf("duplicate sink name: %q", s.name)
+		}
+		if err != nil {
+			releaseSinks(sinks)
+			return nil, err
+		}
+		names[s.name] = true
+		sinks = append(sinks, s)
+	}
+	return sinks, nil
+}
+
+func (s *Sink) retain() {
+	s.refs.Add(1)
+}
+
+// release 释放一个引用，没有 sinkSet 再包含它时释放文件引用；未加入 sinkSet 的直接释放
+func (s *Sink) release() {
+	if s.refs.Add(-1) <= 0 {
+		s.file.release()
+	}
+}
+
+func releaseSinks(sinks []*Sink) {
+	for _, s := range sinks {
+		s.release()
+	}
+}
+
+// emit 按快照中的写入策略输出已编码的一行日志
+func (s *Sink) emit(o *loggerOutput, level LogLevel, line string) {
+	if s.writer == nil {
+		emitLine(o, level, line)
+		return
+	}
+
+	q := o.queue
+	if o.strategy == LoggingSync || s.std || q == nil {
+		writeTo(s.writer, line)
+	} else {
+		q.push(logItem{out: o, level: level, line: line, sink: s})
+	}
+}
+
+// sinkSet 日志器的输出目标列表，修改时整体替换，热路径上只有一次原子读取。
+// With、Named 创建的子日志器通过输出快照使用父日志器当前的 sinkSet，不持有引用；子日志器修改时创建自己的 sinkSet。
+// sinkSet 对其中的每个输出目标持有一个引用，创建它的日志器关闭后释放，之后写入其中文件的日志被丢弃
+type sinkSet struct {
+	owner    *LogsLogger // 创建它的日志器，只有它负责释放引用
+	list     atomic.Pointer[[]*Sink]
+	released atomic.Bool
+}
+
+func (ss *sinkSet) load() []*Sink {
+	if p := ss.list.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// release 释放对其中所有输出目标的引用，只释放一次
+func (ss *sinkSet) release() {
+	if !ss.released.CompareAndSwap(false, true) {
+		return
+	}
+	releaseSinks(ss.load())
+}
+
+// setSinks 替换日志器的输出目标：对新列表中的每个输出目标获取引用，释放旧列表的引用。
+// 使用父日志器的 sinkSet 时创建自己的，不释放父日志器的引用；调用方需持有锁
+func (l *LogsLogger) setSinks(sinks []*Sink) {
+	for _, s := range sinks {
+		s.retain()
+	}
+	ss := l.sinks.Load()
+	if ss == nil || ss.owner != l || ss.released.Load() {
+		ss = &sinkSet{owner: l}
+		l.sinks.Store(ss)
+		if c := l.out.Load(); c != nil && c.owner == l {
+			l.publish() // 子日志器随之使用新的 sinkSet
+		}
+	}
+	if old := ss.list.Swap(&sinks); old != nil {
+		releaseSinks(*old)
+	}
+}
+
+// applySinkRotation 切割参数变化后，让未单独设置切割参数的文件输出使用新参数；调用方需持有锁
+func (l *LogsLogger) applySinkRotation() {
+	ss := l.sinks.Load()
+	if ss == nil {
+		return
+	}
+	for _, s := range ss.load() {
+		if s.file != nil && s.conf != nil {
+			// 同一路径的写入器以最后一次获取时的参数为准，获取后立即释放即可更新参数
+			acquireFileWriter(s.conf.Path, sinkRotation(*s.conf, l.logConf), nil).release()
+		}
+	}
+}
+
+// AddSink 添加输出目标，名称不能与已有的重复。
+// 日志器原来的输出（Mode 对应的输出，名称为 console/file/both）会保留，不需要时可以用 RemoveSink 移除
+func (l *LogsLogger) AddSink(s *Sink) error {
+	if s == nil {
+		return errors.New("sink cannot be nil")
+	}
+
+	mu2.Lock()
+	defer mu2.Unlock()
+
+	o := l.outputs()
+	var current []*Sink
+	if ss := l.sinkSet(o); ss != nil {
+		current = ss.load()
+	} else {
+		current = []*Sink{modeSink(o.conf.Mode)}
+	}
+	for _, x := range current {
+		if x.name == s.name {
+			return fmt.Errorf("duplicate sink name: %q", s.name)
+		}
+	}
+
+	sinks := make([]*Sink, 0, len(current)+1)
+	sinks = append(sinks, current...)
+	sinks = append(sinks, s)
+	l.setSinks(sinks)
+	return nil
+}
+
+// AddSinkConf 按配置创建并添加输出目标，文件的切割参数默认使用日志器的配置
+func (l *LogsLogger) AddSinkConf(conf SinkConf) error {
+	mu2.Lock()
+	o := l.outputs()
+	s, err := openSink(conf, o.conf, o.hooks)
+	mu2.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := l.AddSink(s); err != nil {
+		s.release()
+		return err
+	}
+	return nil
+}
+
+// RemoveSink 移除输出目标，异步队列中发往它的日志会先写完
+func (l *LogsLogger) RemoveSink(name string) error {
+	mu2.Lock()
+	o := l.outputs()
+	ss := l.sinkSet(o)
+	if ss == nil {
+		l.setSinks([]*Sink{modeSink(o.conf.Mode)})
+		ss = l.sinks.Load()
+	}
+	current := ss.load()
+	sinks := make([]*Sink, 0, len(current))
+	var removed *Sink
+	for _, s := range current {
+		if s.name == name {
+			removed = s
+			continue
+		}
+		sinks = append(sinks, s)
+	}
+	if removed == nil {
+		mu2.Unlock()
+		return fmt.Errorf("unknown sink: %q", name)
+	}
+	removed.retain() // 队列中的日志写完之前不关闭文件
+	l.setSinks(sinks)
+	q := o.queue
+	mu2.Unlock()
+
+	if q != nil {
+		q.flush(context.Background())
+	}
+	removed.release()
+	return nil
+}
+
+// Sinks 返回日志器当前的输出目标，可以通过 Sink.SetLevel 修改各自的级别
+func (l *LogsLogger) Sinks() []*Sink {
+	mu2.Lock()
+	defer mu2.Unlock()
+
+	o := l.outputs()
+	ss := l.sinkSet(o)
+	if ss == nil {
+		l.setSinks([]*Sink{modeSink(o.conf.Mode)})
+		ss = l.sinks.Load()
+	}
+	return append([]*Sink(nil), ss.load()...)
+}
+
+// 全局日志器的输出目标
+func AddSink(s *Sink) error {
+	return globalLogger.AddSink(s)
+}
+
+func AddSinkConf(conf SinkConf) error {
+	return globalLogger.AddSinkConf(conf)
+}
+
+func RemoveSink(name string) error {
+	return globalLogger.RemoveSink(name)
+}
+
+func Sinks() []*Sink {
+	return globalLogger.Sinks()
+}
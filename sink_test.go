@@ -0,0 +1,147 @@
+package logs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fileRefs 返回 path 对应的共享写入器上的引用数，未登记（已关闭）时返回 0
+func fileRefs(t *testing.T, path string) int {
+	t.Helper()
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileWritersMu.Lock()
+	defer fileWritersMu.Unlock()
+	if w, ok := fileWriters[abs]; ok {
+		return len(w.handles)
+	}
+	return 0
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestChildSinksKeepParentFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	parent, err := NewLogger(LogConf{Sinks: []SinkConf{{Type: SinkFile, Path: path}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := parent.With("child", true)
+	if err := child.AddSinkConf(SinkConf{Type: SinkStderr, Level: int(LogLevelPanic)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := fileRefs(t, path); n != 1 {
+		t.Fatalf("refs after child close = %d, want 1", n)
+	}
+	parent.Info("parent")
+	if err := parent.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := fileRefs(t, path); n != 0 {
+		t.Fatalf("refs after parent close = %d, want 0", n)
+	}
+	if got := readFile(t, path); !strings.Contains(got, "parent") {
+		t.Fatalf("file = %q", got)
+	}
+}
+
+func TestChildRemoveSinkKeepsParentSinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	parent, err := NewLogger(LogConf{Sinks: []SinkConf{{Name: "app", Type: SinkFile, Path: path}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parent.Close(context.Background())
+
+	child := parent.Named("child")
+	if err := child.RemoveSink("app"); err != nil {
+		t.Fatal(err)
+	}
+	if len(parent.Sinks()) != 1 || len(child.Sinks()) != 0 {
+		t.Fatalf("parent sinks = %d, child sinks = %d", len(parent.Sinks()), len(child.Sinks()))
+	}
+	if n := fileRefs(t, path); n != 1 {
+		t.Fatalf("refs = %d, want 1", n)
+	}
+	parent.Info("still open")
+	if err := parent.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); !strings.Contains(got, "still open") {
+		t.Fatalf("file = %q", got)
+	}
+}
+
+func TestParentCloseKeepsChildSinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	parent, err := NewLogger(LogConf{Sinks: []SinkConf{{Type: SinkFile, Path: path}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := parent.With("child", true)
+	if err := child.AddSinkConf(SinkConf{Type: SinkStderr, Level: int(LogLevelPanic)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := parent.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := fileRefs(t, path); n != 1 {
+		t.Fatalf("refs after parent close = %d, want 1", n)
+	}
+	if err := child.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Close(context.Background()); err != nil { // 多次关闭
+		t.Fatal(err)
+	}
+	if n := fileRefs(t, path); n != 0 {
+		t.Fatalf("refs after child close = %d, want 0", n)
+	}
+}
+
+// 父日志器关闭后，未修改输出目标的子日志器的写入被丢弃，日志文件不会在登记之外重新打开
+func TestChildWriteAfterParentClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	parent, err := NewLogger(LogConf{Sinks: []SinkConf{{Type: SinkFile, Path: path}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := parent.With("child", true)
+	child.Info("before close")
+
+	if err := parent.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); !strings.Contains(got, "before close") {
+		t.Fatalf("file = %q", got)
+	}
+	// 文件被清理或归档后，子日志器的写入不会重新创建它
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	child.Info("after close")
+	child.Error("after close")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("file reopened after parent close: %v", err)
+	}
+	if n := fileRefs(t, path); n != 0 {
+		t.Fatalf("refs = %d, want 0", n)
+	}
+}
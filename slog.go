@@ -0,0 +1,240 @@
+package logs
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+)
+
+// Fatal、Panic 在 slog 中没有对应级别，使用比 slog.LevelError 更高的自定义级别
+const (
+	SlogLevelFatal slog.Level = slog.LevelError + 4
+	SlogLevelPanic slog.Level = slog.LevelError + 8
+)
+
+// SlogHandler 以 LogsLogger 为后端的 slog.Handler，输出复用日志器的切割、编码和级别配置
+//
+//	slog.SetDefault(slog.New(logs.NewSlogHandler(logger)))
+type SlogHandler struct {
+	logger *LogsLogger
+	frames []slogFrame // frames[0] 为顶层，之后每个元素对应一次 WithGroup
+}
+
+type slogFrame struct {
+	group  string
+	fields []Field
+}
+
+// NewSlogHandler 创建以 logger 为后端的 slog.Handler，logger 为 nil 时使用全局日志器
+func NewSlogHandler(logger *LogsLogger) *SlogHandler {
+	if logger == nil {
+		logger = globalLogger
+	}
+	return &SlogHandler{logger: logger, frames: []slogFrame{{}}}
+}
+
+// FromSlogLevel 将 slog 级别映射为 LogLevel
+func FromSlogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return LogLevelDebug
+	case level < slog.LevelWarn:
+		return LogLevelInfo
+	case level < slog.LevelError:
+		return LogLevelWarn
+	case level < SlogLevelFatal:
+		return LogLevelError
+	case level < SlogLevelPanic:
+		return LogLevelFatal
+	default:
+		return LogLevelPanic
+	}
+}
+
+// ToSlogLevel 将 LogLevel 映射为 slog 级别
+func ToSlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelInfo:
+		return slog.LevelInfo
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	case LogLevelFatal:
+		return SlogLevelFatal
+	default:
+		return SlogLevelPanic
+	}
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.enabled(FromSlogLevel(level))
+}
+
+// Handle 输出一条 slog 记录，Fatal、Panic 级别只记录日志，不会退出程序或触发 panic
+func (h *SlogHandler) Handle(ctx context.Context, sr slog.Record) error {
+	level := FromSlogLevel(sr.Level)
+
+	fields := make([]Field, 0, sr.NumAttrs())
+	sr.Attrs(func(a slog.Attr) bool {
+		fields = appendSlogAttr(fields, a)
+		return true
+	})
+
+	// 由内向外组装分组，空分组按照 slog 的约定省略
+	for i := len(h.frames) - 1; i >= 0; i-- {
+		frame := h.frames[i]
+		inner := mergeFields(frame.fields, fields)
+		if i == 0 {
+			fields = inner
+		} else if len(inner) == 0 {
+			fields = nil
+		} else {
+			fields = []Field{Group(frame.group, inner...)}
+		}
+	}
+
+	r := newRecord(h.logger, level, mergeFields(contextFields(ctx), fields))
+	r.Message = sr.Message
+	r.Args = []interface{}{sr.Message}
+	if !sr.Time.IsZero() {
+		r.Time = sr.Time
+	}
+	if sr.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{sr.PC}).Next()
+		r.Caller = Caller{PC: sr.PC, File: frame.File, Line: frame.Line}
+	}
+	for _, f := range r.Fields {
+		if err, ok := f.Value.(error); ok {
+			r.Err = err
+			break
+		}
+	}
+
+	emitRecord(h.logger, r)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	frames := make([]slogFrame, len(h.frames))
+	copy(frames, h.frames)
+
+	last := &frames[len(frames)-1]
+	fields := make([]Field, len(last.fields), len(last.fields)+len(attrs))
+	copy(fields, last.fields)
+	for _, a := range attrs {
+		fields = appendSlogAttr(fields, a)
+	}
+	last.fields = fields
+
+	return &SlogHandler{logger: h.logger, frames: frames}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	frames := make([]slogFrame, len(h.frames), len(h.frames)+1)
+	copy(frames, h.frames)
+	frames = append(frames, slogFrame{group: name})
+
+	return &SlogHandler{logger: h.logger, frames: frames}
+}
+
+// appendSlogAttr 将 slog.Attr 转换为字段，分组转换为嵌套字段
+func appendSlogAttr(fields []Field, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		var group []Field
+		for _, ga := range a.Value.Group() {
+			group = appendSlogAttr(group, ga)
+		}
+		if len(group) == 0 {
+			return fields
+		}
+		if a.Key == "" {
+			return append(fields, group...) // 无键的分组直接展开
+		}
+		return append(fields, Group(a.Key, group...))
+	}
+
+	return append(fields, Field{Key: a.Key, Value: a.Value.Any()})
+}
+
+// NewLoggerFromHandler 创建一个以 slog.Handler 为后端的 LogsLogger，
+// 便于接收 LogsLogger 的库将日志送入已有的 slog 处理链
+func NewLoggerFromHandler(handler slog.Handler) *LogsLogger {
+	logger := &LogsLogger{
+		encoder:          &PlainEncoder{},
+		output:           io.Discard,
+		logFlags:         LogFlagsCommon,
+		logConf:          defaultLogConf,
+		logWriteStrategy: LoggingSync,
+		handler:          handler,
+	}
+	logger.logConf.Level = int(LogLevelDebug) // 级别由 Handler.Enabled 决定
+	logger.level.Store(NewAtomicLevel(LogLevelDebug))
+
+	logger.initLoggers(io.Discard)
+	return logger
+}
+
+// handleSlogRecord 将日志记录转换为 slog.Record 并交给 handler
+func handleSlogRecord(handler slog.Handler, r *Record) {
+	ctx := context.Background()
+	level := ToSlogLevel(r.Level)
+	if !handler.Enabled(ctx, level) {
+		return
+	}
+
+	sr := slog.NewRecord(r.Time, level, r.Message, r.Caller.PC)
+	if r.LoggerName != "" {
+		sr.AddAttrs(slog.String("logger", r.LoggerName))
+	}
+	for _, f := range r.Fields {
+		sr.AddAttrs(fieldToSlogAttr(f))
+	}
+	if r.Err != nil && !hasErrorField(r.Fields) {
+		sr.AddAttrs(slog.Any("error", r.Err))
+	}
+	for _, f := range errorFields(r.Err) {
+		sr.AddAttrs(fieldToSlogAttr(f))
+	}
+	if r.Stack != "" {
+		sr.AddAttrs(slog.String("stacktrace", r.Stack))
+	}
+
+	handler.Handle(ctx, sr)
+}
+
+func fieldToSlogAttr(f Field) slog.Attr {
+	if group, ok := f.Value.([]Field); ok {
+		attrs := make([]any, 0, len(group))
+		for _, gf := range group {
+			attrs = append(attrs, fieldToSlogAttr(gf))
+		}
+		return slog.Group(f.Key, attrs...)
+	}
+	return slog.Any(f.Key, f.Value)
+}
+
+func hasErrorField(fields []Field) bool {
+	for _, f := range fields {
+		if _, ok := f.Value.(error); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type secret string
+
+func (secret) LogValue() slog.Value { return slog.StringValue("***") }
+
+// 分组、WithAttrs 按 slog 的约定嵌套为 JSON 对象，空分组省略
+func TestSlogHandlerGroups(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		log  func(h slog.Handler)
+		want string
+	}{
+		{
+			"attrs",
+			func(h slog.Handler) { slog.New(h).Info("m", "a", 1, slog.Bool("b", true)) },
+			`"message":"m","a":1,"b":true}`,
+		},
+		{
+			"with attrs and group",
+			func(h slog.Handler) {
+				h = h.WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g").WithAttrs([]slog.Attr{slog.Int("b", 2)})
+				slog.New(h).Info("m", "c", 3)
+			},
+			`"message":"m","a":1,"g":{"b":2,"c":3}}`,
+		},
+		{
+			"nested groups",
+			func(h slog.Handler) { slog.New(h.WithGroup("g1").WithGroup("g2")).Info("m", "x", 1) },
+			`"message":"m","g1":{"g2":{"x":1}}}`,
+		},
+		{
+			"empty group omitted",
+			func(h slog.Handler) { slog.New(h.WithGroup("g")).Info("m") },
+			`"message":"m"}`,
+		},
+		{
+			"group attrs",
+			func(h slog.Handler) {
+				slog.New(h).Info("m", slog.Group("req", "id", 7), slog.Group("", "k", "v"), slog.Group("empty"), slog.Attr{})
+			},
+			`"message":"m","req":{"id":7},"k":"v"}`,
+		},
+		{
+			"log valuer and error",
+			func(h slog.Handler) { slog.New(h).Error("m", "token", secret("x"), "err", errors.New("boom")) },
+			`"message":"m","token":"***","err":"boom","error_type":"*errors.errorString"}`,
+		},
+	} {
+		l, buf := newBufferLogger(t)
+		if err := l.SetEncoding(LogEncodingJSON); err != nil {
+			t.Fatal(err)
+		}
+		tc.log(NewSlogHandler(l))
+		if got := strings.TrimSpace(buf.String()); !strings.HasSuffix(got, tc.want) {
+			t.Errorf("%s:\n got %s\nwant suffix %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSlogLevels(t *testing.T) {
+	for _, tc := range []struct {
+		slog slog.Level
+		want LogLevel
+	}{
+		{slog.LevelDebug - 4, LogLevelDebug},
+		{slog.LevelDebug, LogLevelDebug},
+		{slog.LevelInfo, LogLevelInfo},
+		{slog.LevelInfo + 2, LogLevelInfo},
+		{slog.LevelWarn, LogLevelWarn},
+		{slog.LevelError, LogLevelError},
+		{SlogLevelFatal, LogLevelFatal},
+		{SlogLevelPanic, LogLevelPanic},
+		{SlogLevelPanic + 100, LogLevelPanic},
+	} {
+		if got := FromSlogLevel(tc.slog); got != tc.want {
+			t.Errorf("FromSlogLevel(%v) = %v, want %v", tc.slog, got, tc.want)
+		}
+	}
+	for lv := LogLevelDebug; lv <= LogLevelPanic; lv++ {
+		if got := FromSlogLevel(ToSlogLevel(lv)); got != lv {
+			t.Errorf("FromSlogLevel(ToSlogLevel(%v)) = %v", lv, got)
+		}
+	}
+
+	l, _ := newBufferLogger(t)
+	if err := l.SetLogLevel(LogLevelWarn); err != nil {
+		t.Fatal(err)
+	}
+	h := NewSlogHandler(l)
+	if h.Enabled(context.Background(), slog.LevelInfo) || !h.Enabled(context.Background(), slog.LevelWarn) {
+		t.Fatal("Enabled does not follow the logger level")
+	}
+}
+
+// 以 slog.Handler 为后端的 LogsLogger：字段、名称和错误都转换为 slog 属性
+func TestLoggerFromHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	l := NewLoggerFromHandler(h)
+
+	for _, tc := range []struct {
+		log  func()
+		want string
+	}{
+		{func() { l.Debugw("m", "k", "v") }, `{"level":"DEBUG","msg":"m","k":"v"}`},
+		{func() { l.With("a", 1).Infow("m", Group("g", F("b", 2))) }, `{"level":"INFO","msg":"m","a":1,"g":{"b":2}}`},
+		{func() { l.Named("db").Warn("m") }, `{"level":"WARN","msg":"m","logger":"db"}`},
+		{func() { l.Errorw("m", "err", errors.New("boom")) }, `{"level":"ERROR","msg":"m","err":"boom","error_type":"*errors.errorString"}`},
+	} {
+		buf.Reset()
+		tc.log()
+		if got := strings.TrimSpace(buf.String()); got != tc.want {
+			t.Errorf("got  %s\nwant %s", got, tc.want)
+		}
+	}
+}
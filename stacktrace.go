@@ -0,0 +1,95 @@
+package logs
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// pkgPath 本包的导入路径，用于从调用栈中去掉本包的帧
+var pkgPath = reflect.TypeOf(LogsLogger{}).PkgPath()
+
+// captureStack 获取当前 goroutine 的调用栈，去掉本包以及 runtime 入口的帧，
+// 格式与 panic 输出相同：每帧两行，"函数名" 和 "\t文件:行号"
+func captureStack(skip int) string {
+	stack, _ := stackAndCaller(skip + 1)
+	return stack
+}
+
+// stackAndCaller 与 captureStack 相同，同时返回保留下来的第一帧（在 defer 中调用时即为 panic 发生的位置）
+func stackAndCaller(skip int) (string, Caller) {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var sb strings.Builder
+	var first Caller
+	for {
+		frame, more := frames.Next()
+		// 栈顶的 runtime 帧（如 map 赋值、越界检查）属于 panic 的实现细节，从用户代码开始记录
+		leading := sb.Len() == 0 && (strings.HasPrefix(frame.Function, "runtime.") || strings.HasPrefix(frame.Function, "internal/runtime/"))
+		if !leading && !isInternalFrame(frame.Function) {
+			if sb.Len() > 0 {
+				sb.WriteByte('\n')
+			} else {
+				first = Caller{PC: frame.PC, File: frame.File, Line: frame.Line}
+			}
+			sb.WriteString(frame.Function + "\n\t" + frame.File + ":" + strconv.Itoa(frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return sb.String(), first
+}
+
+// isInternalFrame 是否是本包、runtime 入口或 panic 处理的帧
+func isInternalFrame(function string) bool {
+	switch function {
+	case "runtime.goexit", "runtime.main", "runtime.gopanic", "runtime.sigpanic":
+		return true
+	}
+	if strings.HasPrefix(function, "runtime.panic") || strings.HasPrefix(function, "runtime.goPanic") {
+		return true
+	}
+	return strings.HasPrefix(function, pkgPath+".")
+}
+
+// appendPlainStack 将调用栈缩进后追加到日志的下一行
+func appendPlainStack(sb *strings.Builder, stack string) {
+	if stack == "" {
+		return
+	}
+	for _, line := range strings.Split(stack, "\n") {
+		sb.WriteString("\n\t" + line)
+	}
+}
+
+// SetStacktraceLevel 设置附加调用栈的最低级别，如 LogLevelError；传入 LogLevelDebug（0）时关闭
+func (l *LogsLogger) SetStacktraceLevel(level LogLevel) error {
+	mu2.Lock()
+	defer mu2.Unlock()
+
+	if level < LogLevelDebug || level > LogLevelPanic {
+		return errors.New("invalid log level")
+	}
+	l.own()
+	l.logConf.StacktraceLevel = int(level)
+	l.publish()
+	return nil
+}
+
+// 全局日志器附加调用栈的最低级别
+func SetStacktraceLevel(level LogLevel) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if level < LogLevelDebug || level > LogLevelPanic {
+		return errors.New("invalid log level")
+	}
+	globalLogger.logConf.StacktraceLevel = int(level)
+	globalLogger.publish()
+	return nil
+}
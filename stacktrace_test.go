@@ -0,0 +1,81 @@
+package logs
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestIsInternalFrame(t *testing.T) {
+	for _, tc := range []struct {
+		function string
+		want     bool
+	}{
+		{pkgPath + ".(*LogsLogger).Error", true},
+		{pkgPath + ".outputLog", true},
+		{"runtime.gopanic", true},
+		{"runtime.panicmem", true},
+		{"runtime.goexit", true},
+		{"runtime.mapassign", false}, // 只在栈顶时去掉
+		{"main.main", false},
+		{pkgPath + "x.Func", false},
+		{"testing.tRunner", false},
+	} {
+		if got := isInternalFrame(tc.function); got != tc.want {
+			t.Errorf("isInternalFrame(%q) = %v, want %v", tc.function, got, tc.want)
+		}
+	}
+}
+
+// 达到 StacktraceLevel 的日志附加调用栈，0 表示关闭；调用栈中没有本包的帧
+func TestStacktraceLevel(t *testing.T) {
+	for _, tc := range []struct {
+		threshold LogLevel
+		level     LogLevel
+		want      bool
+	}{
+		{LogLevelDebug, LogLevelError, false}, // 关闭
+		{LogLevelWarn, LogLevelInfo, false},
+		{LogLevelWarn, LogLevelWarn, true},
+		{LogLevelWarn, LogLevelError, true},
+		{LogLevelError, LogLevelWarn, false},
+		{LogLevelError, LogLevelError, true},
+		{LogLevelPanic, LogLevelError, false},
+	} {
+		l, buf := newBufferLogger(t)
+		if err := l.SetEncoding(LogEncodingJSON); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.SetLogLevel(LogLevelDebug); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.SetStacktraceLevel(tc.threshold); err != nil {
+			t.Fatal(err)
+		}
+		switch tc.level {
+		case LogLevelInfo:
+			l.Info("m")
+		case LogLevelWarn:
+			l.Warn("m")
+		case LogLevelError:
+			l.Error("m")
+		}
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("%q: %v", buf.String(), err)
+		}
+		stack, ok := entry["stacktrace"].(string)
+		if ok != tc.want {
+			t.Errorf("threshold %v, level %v: stacktrace=%v, want %v", tc.threshold, tc.level, ok, tc.want)
+			continue
+		}
+		if ok && (strings.Contains(stack, pkgPath+".") || !strings.Contains(stack, "testing.tRunner")) {
+			t.Errorf("threshold %v, level %v: stack not trimmed:\n%s", tc.threshold, tc.level, stack)
+		}
+	}
+
+	if err := (&LogsLogger{}).SetStacktraceLevel(LogLevelPanic + 1); err == nil {
+		t.Fatal("SetStacktraceLevel accepted an invalid level")
+	}
+}
@@ -0,0 +1,125 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 时间格式预设，也可以直接使用 Go 的时间布局字符串，如 "2006-01-02 15:04:05.000"
+const (
+	TimeFormatRFC3339     = "rfc3339"     // 2006-01-02T15:04:05Z07:00
+	TimeFormatRFC3339Nano = "rfc3339nano" // 2006-01-02T15:04:05.999999999Z07:00
+	TimeFormatISO8601     = "iso8601"     // 2006-01-02T15:04:05.000Z0700
+	TimeFormatEpoch       = "epoch"       // Unix 秒
+	TimeFormatEpochMillis = "epoch_ms"    // Unix 毫秒
+	TimeFormatEpochMicros = "epoch_us"    // Unix 微秒
+	TimeFormatEpochNanos  = "epoch_ns"    // Unix 纳秒
+)
+
+// resolveTimeLayout 将预设名称转换为 Go 时间布局，epoch 系列保持原名称
+func resolveTimeLayout(format string) string {
+	switch strings.ToLower(format) {
+	case TimeFormatRFC3339:
+		return time.RFC3339
+	case TimeFormatRFC3339Nano:
+		return time.RFC3339Nano
+	case TimeFormatISO8601:
+		return "2006-01-02T15:04:05.000Z0700"
+	case TimeFormatEpoch, TimeFormatEpochMillis, TimeFormatEpochMicros, TimeFormatEpochNanos:
+		return strings.ToLower(format)
+	default:
+		return format
+	}
+}
+
+// timeFormatPresets 可用的时间格式预设
+var timeFormatPresets = []string{
+	TimeFormatRFC3339, TimeFormatRFC3339Nano, TimeFormatISO8601,
+	TimeFormatEpoch, TimeFormatEpochMillis, TimeFormatEpochMicros, TimeFormatEpochNanos,
+}
+
+// validTimeFormat 校验时间格式：空字符串、预设名称，或至少包含一个参考时间元素（2006、01、15 等）的 Go 时间布局。
+// 只由字母、数字和下划线组成的格式视为预设名称
+func validTimeFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	for _, p := range timeFormatPresets {
+		if strings.EqualFold(format, p) {
+			return nil
+		}
+	}
+	if isPresetName(format) {
+		return fmt.Errorf("unknown time format %q: want one of %s or a Go time layout such as \"2006-01-02 15:04:05\"",
+			format, strings.Join(timeFormatPresets, ", "))
+	}
+	// 用两个各项都不同的时间格式化，结果都与布局相同说明布局中没有参考时间元素
+	t1 := time.Date(2001, 11, 23, 22, 33, 44, 123456789, time.FixedZone("XYZ", 5*3600+1800))
+	t2 := time.Date(1999, 3, 7, 7, 8, 9, 987654321, time.FixedZone("ABC", -3*3600))
+	if t1.Format(format) == format && t2.Format(format) == format {
+		return fmt.Errorf("time layout %q has no reference time component (2006, 01, 02, 15, 04, 05, ...)", format)
+	}
+	return nil
+}
+
+// isPresetName 格式是否形如预设名称：以字母开头，只包含字母、数字和下划线
+func isPresetName(format string) bool {
+	for i, c := range format {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '_'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// loadTimeZone 解析时区名称，空字符串表示本地时区
+func loadTimeZone(name string) (*time.Location, error) {
+	switch name {
+	case "", "Local", "local":
+		return time.Local, nil
+	case "UTC", "utc":
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.New("invalid time zone: " + name)
+	}
+	return loc, nil
+}
+
+// epochTime 如果 layout 是 epoch 预设，返回对应的整数时间戳
+func epochTime(t time.Time, layout string) (int64, bool) {
+	switch layout {
+	case TimeFormatEpoch:
+		return t.Unix(), true
+	case TimeFormatEpochMillis:
+		return t.UnixMilli(), true
+	case TimeFormatEpochMicros:
+		return t.UnixMicro(), true
+	case TimeFormatEpochNanos:
+		return t.UnixNano(), true
+	}
+	return 0, false
+}
+
+// recordTime 返回应用了 LUTC 标志后的时间
+func recordTime(r *Record) time.Time {
+	if r.Flags&LUTC != 0 {
+		return r.Time.UTC()
+	}
+	return r.Time
+}
+
+// formatTime 按布局格式化时间，支持 epoch 预设
+func formatTime(t time.Time, layout string) string {
+	if n, ok := epochTime(t, layout); ok {
+		return strconv.FormatInt(n, 10)
+	}
+	return t.Format(layout)
+}
@@ -0,0 +1,66 @@
+package logs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatTimePresets(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	for _, tc := range []struct {
+		format string
+		want   string
+	}{
+		{TimeFormatRFC3339, "2024-01-02T03:04:05Z"},
+		{"RFC3339", "2024-01-02T03:04:05Z"}, // 预设不区分大小写
+		{TimeFormatRFC3339Nano, "2024-01-02T03:04:05.123456789Z"},
+		{TimeFormatISO8601, "2024-01-02T03:04:05.123Z"},
+		{TimeFormatEpoch, "1704164645"},
+		{TimeFormatEpochMillis, "1704164645123"},
+		{TimeFormatEpochMicros, "1704164645123456"},
+		{"EPOCH_NS", "1704164645123456789"},
+		{"2006/01/02 15:04", "2024/01/02 03:04"},
+	} {
+		if got := formatTime(ts, resolveTimeLayout(tc.format)); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.format, got, tc.want)
+		}
+	}
+}
+
+// 时间格式和时区同时作用于 plain、JSON、logfmt 编码，LUTC 标志优先于时区
+func TestTimeFormatAndZone(t *testing.T) {
+	if _, err := loadTimeZone("Asia/Shanghai"); err != nil {
+		t.Skip("time zone database not available")
+	}
+	for _, tc := range []struct {
+		encoding string
+		format   string
+		utc      bool
+		want     string
+	}{
+		{LogEncodingPlain, "Z07:00", false, "+08:00 [INFO] "},
+		{LogEncodingPlain, "Z07:00", true, "Z [INFO] "},
+		{LogEncodingJSON, "-0700 MST", false, `{"timestamp":"+0800 CST",`},
+		{LogEncodingJSON, TimeFormatEpoch, false, `{"timestamp":1`},
+		{LogEncodingLogfmt, "Z07:00", false, "ts=+08:00 "},
+	} {
+		l, buf := newBufferLogger(t)
+		if err := l.SetEncoding(tc.encoding); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.SetTimeZone("Asia/Shanghai"); err != nil {
+			t.Fatal(err)
+		}
+		l.SetTimeFormat(tc.format)
+		if tc.utc {
+			if err := l.SetFlags(LogFlagsCommon | LUTC); err != nil {
+				t.Fatal(err)
+			}
+		}
+		l.Info("m")
+		if got := buf.String(); !strings.HasPrefix(got, tc.want) {
+			t.Errorf("%s %q utc=%v: got %q, want prefix %q", tc.encoding, tc.format, tc.utc, got, tc.want)
+		}
+	}
+}
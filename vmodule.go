@@ -0,0 +1,129 @@
+package logs
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// vmoduleRule 一条按调用者文件设置级别的规则
+type vmoduleRule struct {
+	pattern string
+	level   LogLevel
+}
+
+// vmoduleSet 一组规则以及按调用位置（PC）缓存的匹配结果；修改规则时整体替换，旧缓存随之失效
+type vmoduleSet struct {
+	spec  string
+	rules []vmoduleRule
+	cache sync.Map // uintptr -> vmoduleMatch
+}
+
+type vmoduleMatch struct {
+	level LogLevel
+	ok    bool
+}
+
+// vmodule 当前生效的规则，为空时不做任何检查
+var vmodule atomic.Pointer[vmoduleSet]
+
+// SetVModule 按调用者文件设置级别，规则格式为 "storage/*=debug,cmd/server.go=warn"，传入空字符串时清空。
+// 模式按 path.Match 匹配调用者相对于项目根目录的路径（也匹配路径的任意后缀，以及去掉 .go 后的路径），
+// 不含 "/" 的模式还会匹配文件名；多条规则匹配时以第一条为准。
+// 匹配到的规则优先于名称级别和日志器自身的级别，既可以放开也可以收紧该文件的日志
+func SetVModule(spec string) error {
+	rules, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		vmodule.Store(nil)
+		return nil
+	}
+	vmodule.Store(&vmoduleSet{spec: spec, rules: rules})
+	return nil
+}
+
+// VModule 返回当前的规则
+func VModule() string {
+	if vs := vmodule.Load(); vs != nil {
+		return vs.spec
+	}
+	return ""
+}
+
+func parseVModule(spec string) ([]vmoduleRule, error) {
+	var rules []vmoduleRule
+	for _, item := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == ' ' }) {
+		pattern, value, ok := strings.Cut(item, "=")
+		pattern = filepath.ToSlash(strings.TrimSpace(pattern))
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("invalid vmodule rule %q, expected pattern=level", item)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid vmodule pattern %q: %v", pattern, err)
+		}
+		level, err := ParseLogLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vmodule rule %q: %v", item, err)
+		}
+		rules = append(rules, vmoduleRule{pattern: pattern, level: level})
+	}
+	return rules, nil
+}
+
+// vmoduleLevel 返回调用位置匹配的级别，skip 的含义与 callerAt 相同。
+// 没有规则时只有一次原子读取；同一个调用位置只在第一次时匹配规则
+func vmoduleLevel(skip int) (LogLevel, bool) {
+	vs := vmodule.Load()
+	if vs == nil {
+		return 0, false
+	}
+
+	var pcs [1]uintptr
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		return 0, false
+	}
+	if m, ok := vs.cache.Load(pcs[0]); ok {
+		return m.(vmoduleMatch).level, m.(vmoduleMatch).ok
+	}
+
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	m := vs.match(Caller{File: frame.File}.RelativePath())
+	vs.cache.Store(pcs[0], m)
+	return m.level, m.ok
+}
+
+func (vs *vmoduleSet) match(file string) vmoduleMatch {
+	file = filepath.ToSlash(file)
+	for _, rule := range vs.rules {
+		if vmoduleMatchPath(rule.pattern, file) {
+			return vmoduleMatch{level: rule.level, ok: true}
+		}
+	}
+	return vmoduleMatch{}
+}
+
+// vmoduleMatchPath 用 pattern 匹配 file 及其每个以 "/" 分隔的后缀，同时尝试去掉 .go 扩展名
+func vmoduleMatchPath(pattern, file string) bool {
+	if !strings.Contains(pattern, "/") {
+		file = path.Base(file)
+	}
+	for {
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, strings.TrimSuffix(file, ".go")); ok {
+			return true
+		}
+		i := strings.IndexByte(file, '/')
+		if i < 0 {
+			return false
+		}
+		file = file[i+1:]
+	}
+}
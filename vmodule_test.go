@@ -0,0 +1,97 @@
+package logs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVModuleMatchPath(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"storage/*", "internal/storage/db.go", true}, // 匹配路径后缀
+		{"storage/*", "storage/sub/db.go", false},     // * 不跨越目录
+		{"cmd/server.go", "cmd/server.go", true},
+		{"cmd/server", "cmd/server.go", true}, // 去掉 .go
+		{"server", "cmd/server.go", true},     // 不含 / 时匹配文件名
+		{"serv*", "cmd/server.go", true},
+		{"server", "cmd/server/main.go", false},
+		{"cmd/*", "cmd/server/main.go", false},
+		{"*/main", "cmd/server/main.go", true},
+		{"other/*", "cmd/server.go", false},
+	} {
+		if got := vmoduleMatchPath(tc.pattern, tc.file); got != tc.want {
+			t.Errorf("vmoduleMatchPath(%q, %q) = %v, want %v", tc.pattern, tc.file, got, tc.want)
+		}
+	}
+}
+
+func TestParseVModule(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		want []vmoduleRule
+		ok   bool
+	}{
+		{"", nil, true},
+		{"storage/*=debug, cmd/server.go=WARN", []vmoduleRule{{"storage/*", LogLevelDebug}, {"cmd/server.go", LogLevelWarn}}, true},
+		{"server", nil, false},
+		{"=debug", nil, false},
+		{"server=loud", nil, false},
+		{"[=debug", nil, false},
+	} {
+		got, err := parseVModule(tc.spec)
+		if (err == nil) != tc.ok || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseVModule(%q) = %v, %v; want %v, ok=%v", tc.spec, got, err, tc.want, tc.ok)
+		}
+	}
+}
+
+// logFromOneSite 每个级别固定使用同一个调用位置，用来检查修改规则后按调用位置的缓存失效
+func logFromOneSite(l *LogsLogger, level LogLevel, msg string) {
+	switch level {
+	case LogLevelDebug:
+		l.Debug(msg)
+	case LogLevelWarn:
+		l.Warn(msg)
+	default:
+		l.Info(msg)
+	}
+}
+
+// 第一条匹配的规则生效，规则可以放开或收紧日志器自身的级别，修改规则后同一调用位置使用新规则
+func TestVModuleLevels(t *testing.T) {
+	t.Cleanup(func() { SetVModule("") })
+	l, buf := newBufferLogger(t)
+	if err := l.SetLogLevel(LogLevelInfo); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []struct {
+		spec  string
+		level LogLevel
+		want  bool
+	}{
+		{"", LogLevelDebug, false},
+		{"", LogLevelInfo, true},
+		{"vmodule_test=debug", LogLevelDebug, true},
+		{"vmodule_test.go=warn", LogLevelInfo, false},
+		{"vmodule_test.go=warn", LogLevelWarn, true},
+		{"vmodule*=error,vmodule_test=debug", LogLevelWarn, false}, // 第一条规则优先
+		{"other.go=debug", LogLevelDebug, false},
+		{"", LogLevelDebug, false},
+	} {
+		if err := SetVModule(tc.spec); err != nil {
+			t.Fatal(err)
+		}
+		buf.Reset()
+		logFromOneSite(l, tc.level, "m")
+		if got := strings.Contains(buf.String(), " m"); got != tc.want {
+			t.Errorf("case %d: vmodule=%q level=%v: logged=%v, want %v", i, tc.spec, tc.level, got, tc.want)
+		}
+		if VModule() != tc.spec {
+			t.Errorf("VModule() = %q, want %q", VModule(), tc.spec)
+		}
+	}
+}